@@ -0,0 +1,175 @@
+// app/controllers/account_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountController handles password changes and resets and email verification
+type AccountController struct {
+	*container.Container
+}
+
+func NewAccountController(app *container.Container) *AccountController {
+	return &AccountController{Container: app}
+}
+
+// ChangePassword changes the password of the current user
+// @Summary Change password
+// @Description Replace the password of the authenticated user, who must confirm the current one
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/password [post]
+func (acc *AccountController) ChangePassword(c *fiber.Ctx) error {
+	var request models.ChangePasswordRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, acc.Validator, request); err != nil {
+		return err
+	}
+
+	user, err := acc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	if err := acc.AccountService.ChangePassword(c.UserContext(), user, request); err != nil {
+		return serviceError(err, "Failed to change password")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Password changed successfully",
+	})
+}
+
+// ForgotPassword mails a password reset token
+// @Summary Request a password reset
+// @Description Mail a single-use token to reset the password of the account registered with the email. The answer is the same whether the account exists or not.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.ForgotPasswordRequest true "Email of the account"
+// @Success 202 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/password/forgot [post]
+func (acc *AccountController) ForgotPassword(c *fiber.Ctx) error {
+	var request models.ForgotPasswordRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, acc.Validator, request); err != nil {
+		return err
+	}
+
+	if err := acc.AccountService.RequestPasswordReset(c.UserContext(), request.Email); err != nil {
+		return serviceError(err, "Failed to send password reset email")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Code:    202,
+		Message: "If the email is registered, a password reset link has been sent to it",
+	})
+}
+
+// ResetPassword sets a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with the token mailed by /auth/password/forgot. Tokens expire and can be used once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/password/reset [post]
+func (acc *AccountController) ResetPassword(c *fiber.Ctx) error {
+	var request models.ResetPasswordRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, acc.Validator, request); err != nil {
+		return err
+	}
+
+	if err := acc.AccountService.ResetPassword(c.UserContext(), request); err != nil {
+		return serviceError(err, "Failed to reset password")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Password reset successfully",
+	})
+}
+
+// SendVerification mails a new email verification token to the current user
+// @Summary Resend the verification email
+// @Description Mail a new token to verify the email of the authenticated user, invalidating the previous one
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 202 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/email/verification [post]
+func (acc *AccountController) SendVerification(c *fiber.Ctx) error {
+	user, err := acc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	if err := acc.AccountService.SendVerification(c.UserContext(), user); err != nil {
+		return serviceError(err, "Failed to send verification email")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Code:    202,
+		Message: "A verification link has been sent to your email",
+	})
+}
+
+// VerifyEmail verifies an email with the token mailed to it
+// @Summary Verify email
+// @Description Confirm the user owns their email with the token mailed on registration or by /auth/email/verification
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /auth/email/verify [post]
+func (acc *AccountController) VerifyEmail(c *fiber.Ctx) error {
+	var request models.VerifyEmailRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, acc.Validator, request); err != nil {
+		return err
+	}
+
+	if err := acc.AccountService.VerifyEmail(c.UserContext(), request); err != nil {
+		return serviceError(err, "Failed to verify email")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Email verified successfully",
+	})
+}
@@ -4,18 +4,18 @@ package controllers
 
 import (
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 // AdminController handles admin related requests
 type AdminController struct {
-	DB *gorm.DB
+	*container.Container
 }
 
-func NewAdminController(db *gorm.DB) *AdminController {
-	return &AdminController{DB: db}
+func NewAdminController(app *container.Container) *AdminController {
+	return &AdminController{Container: app}
 }
 
 // GetDashboard returns the current status of all orders
@@ -29,15 +29,12 @@ func NewAdminController(db *gorm.DB) *AdminController {
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /admin/dashboard [get]
 func (adc *AdminController) GetDashboard(c *fiber.Ctx) error {
-	db := database.GetDB()
-	var orders []models.Order
-	if err := db.Preload("OrderItems").Find(&orders).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to fetch orders",
-		})
+	orders, err := adc.OrderService.Dashboard(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch orders", err)
 	}
 
 	var dashboardOrders []models.OrderDashboard
@@ -59,7 +56,7 @@ func (adc *AdminController) GetDashboard(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(models.DashboardResponse{
-		Status:  "success",
+		Code:    200,
 		Message: "Dashboard data fetched successfully",
 		Orders:  dashboardOrders,
 	})
@@ -76,91 +73,306 @@ func (adc *AdminController) GetDashboard(c *fiber.Ctx) error {
 // @Success 200 {object} models.UpdateOrderStatusResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /admin/orders/{id} [patch]
 func (adc *AdminController) UpdateOrderStatus(c *fiber.Ctx) error {
-	id := c.Params("id")
 	var request models.UpdateOrderStatusRequest
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Bad request",
-		})
+		return apierror.BadRequest("Bad request")
 	}
 
-	// Validate the status value
-	validStatus := map[string]bool{
-		"preparing":  true,
-		"processing": true,
-		"shipped":    true,
-		"delivered":  true,
-	}
-	if !validStatus[request.Status] {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Invalid status value",
-		})
+	if err := validateRequest(c, adc.Validator, request); err != nil {
+		return err
 	}
 
-	db := database.GetDB()
-	var order models.Order
-	if err := db.First(&order, id).Error; err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Order not found",
-		})
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return apierror.BadRequest("Order not found")
 	}
 
-	order.Status = request.Status
-	if err := db.Save(&order).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to update order status",
-		})
+	order, err := adc.OrderService.UpdateStatus(c.UserContext(), uint(orderID), request.Status)
+	if err != nil {
+		return serviceError(err, "Failed to update order status")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.UpdateOrderStatusResponse{
 		Code:    200,
 		Message: "Order status updated successfully",
-		Status:  request.Status,
+		Status:  order.Status,
 	})
 }
 
-// GetAllUsers retrieves all buyers
-// @Summary Retrieve all buyer users
-// @Description Retrieves a list of all buyers, only accessible to administrators
+// GetAllUsers lists the users one page at a time
+// @Summary Retrieve users
+// @Description Retrieves a page of users ordered by ID with their role, creation date, suspension and order count, only accessible to administrators
 // @Tags Admin
 // @Accept json
 // @Produce json
+// @Param q query string false "Part of the username or email, any case"
+// @Param role query string false "Only users with this role" Enums(user, admin)
+// @Param status query string false "Only active or suspended users" Enums(active, suspended)
+// @Param page query int false "Page number, from 1"
+// @Param per_page query int false "Users per page, up to 100"
 // @Success 200 {object} models.GetAllUsersResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /admin/users [get]
 func (adc *AdminController) GetAllUsers(c *fiber.Ctx) error {
-	db := database.GetDB()
-	var users []models.User
-	if err := db.Where("role = ?", "user").Find(&users).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Server error",
-		})
+	var filter models.UserFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return apierror.BadRequest("Bad request")
 	}
 
-	var userResponses []models.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, models.UserResponse{
-			Username: user.Username,
-			Email:    user.Email,
-		})
+	page, err := adc.UserService.Search(c.UserContext(), filter)
+	if err != nil {
+		return serviceError(err, "Server error")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.GetAllUsersResponse{
 		Code:    200,
-		Message: userResponses,
+		Message: page.Users,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+		Total:   page.Total,
+	})
+}
+
+// GetUserCollisions lists the users whose usernames or emails collide once normalized
+// @Summary List identity collisions
+// @Description List the groups of users whose usernames or emails are the same once trimmed, NFKC-normalized and lower-cased. The user holding the normalized form comes first, the others kept theirs as typed and log in with their email exactly as registered.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.IdentityCollisionsResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/collisions [get]
+func (adc *AdminController) GetUserCollisions(c *fiber.Ctx) error {
+	collisions, err := adc.UserService.Collisions(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch identity collisions", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.IdentityCollisionsResponse{
+		Code:    200,
+		Message: collisions,
+	})
+}
+
+// GetUser retrieves a user and their order history
+// @Summary Retrieve a user
+// @Description Retrieves a user by ID with their profile and orders, newest first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserDetailResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id} [get]
+func (adc *AdminController) GetUser(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	detail, err := adc.UserService.Detail(c.UserContext(), uint(userID))
+	if err != nil {
+		return serviceError(err, "Failed to fetch user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.UserDetailResponse{
+		Code:    200,
+		Message: detail,
+	})
+}
+
+// SuspendUser suspends a user
+// @Summary Suspend a user
+// @Description Stop a user from logging in and from using the tokens they already have, until unsuspended
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id}/suspend [post]
+func (adc *AdminController) SuspendUser(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	admin, err := adc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	if _, err := adc.UserService.Suspend(c.UserContext(), admin, uint(userID)); err != nil {
+		return serviceError(err, "Failed to suspend user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "User suspended successfully",
+	})
+}
+
+// UnsuspendUser lifts the suspension of a user
+// @Summary Unsuspend a user
+// @Description Let a suspended user log in and use their tokens again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id}/unsuspend [post]
+func (adc *AdminController) UnsuspendUser(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	if _, err := adc.UserService.Unsuspend(c.UserContext(), uint(userID)); err != nil {
+		return serviceError(err, "Failed to unsuspend user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "User unsuspended successfully",
+	})
+}
+
+// RestoreUser undoes the deletion of a user
+// @Summary Restore a deleted user
+// @Description Bring back a deleted user, unless their data was purged or their username or email has been registered again since
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id}/restore [post]
+func (adc *AdminController) RestoreUser(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	if _, err := adc.UserService.Restore(c.UserContext(), uint(userID)); err != nil {
+		return serviceError(err, "Failed to restore user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "User restored successfully",
+	})
+}
+
+// PurgeUser permanently wipes the personal data of a user
+// @Summary Purge a user
+// @Description Anonymize a user for good, deleting them first if needed. Their orders stay in the trade log. A purged user cannot be restored.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id}/purge [delete]
+func (adc *AdminController) PurgeUser(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	admin, err := adc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	if err := adc.UserService.Purge(c.UserContext(), admin, uint(userID)); err != nil {
+		return serviceError(err, "Failed to purge user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "User purged successfully",
+	})
+}
+
+// DeleteUsers handles the deletion of several users at once
+// @Summary Remove users
+// @Description Delete every user listed, or none of them if any does not exist
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param data body models.DeleteUsersRequest true "IDs of the users to delete"
+// @Success 200 {object} models.DeleteUserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users [delete]
+func (adc *AdminController) DeleteUsers(c *fiber.Ctx) error {
+	var request models.DeleteUsersRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, adc.Validator, request); err != nil {
+		return err
+	}
+
+	if err := adc.UserService.DeleteMany(c.UserContext(), request.Users); err != nil {
+		return serviceError(err, "Failed to delete users")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.DeleteUserResponse{
+		Code:    200,
+		Message: "Users deleted successfully",
 	})
 }
 
@@ -174,35 +386,19 @@ func (adc *AdminController) GetAllUsers(c *fiber.Ctx) error {
 // @Success 200 {object} models.DeleteUserResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /admin/users/{id} [delete]
 func (adc *AdminController) DeleteUser(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	db := database.GetDB()
-	var user models.User
-
-	// Find the user by ID
-	if err := db.First(&user, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-				Code:    400,
-				Message: "User not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to find user",
-		})
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("User not found")
 	}
 
-	// Delete the user
-	if err := db.Delete(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to delete user",
-		})
+	if err := adc.UserService.Delete(c.UserContext(), uint(userID)); err != nil {
+		return serviceError(err, "Failed to delete user")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.DeleteUserResponse{
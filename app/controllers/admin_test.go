@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
@@ -18,7 +20,7 @@ import (
 )
 
 func TestGetAllUsers(t *testing.T) {
-	app := fiber.New()
+	app := newTestApp()
 
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -37,18 +39,22 @@ func TestGetAllUsers(t *testing.T) {
 		t.Fatalf("Failed to open gorm db: %s", err)
 	}
 
-	database.SetDB(gormDB)
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "users" WHERE \(LOWER\(username\) LIKE \$1 OR LOWER\(email\) LIKE \$2\) AND role = \$3 AND "users"\."deleted_at" IS NULL`).
+		WithArgs("%user\\_%", "%user\\_%", "user").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	rows := sqlmock.NewRows([]string{"id", "username", "email", "role", "created_at", "suspended_at", "order_count"}).
+		AddRow(11, "user_11", "user11@example.com", "user", created, nil, 3).
+		AddRow(12, "user_12", "user12@example.com", "user", created, created, 0)
+	mock.ExpectQuery(`SELECT users\.id, users\.username, .*\(SELECT COUNT\(\*\) FROM orders WHERE orders\.user_id = users\.id AND orders\.deleted_at IS NULL\) AS order_count FROM "users" WHERE .* ORDER BY users\.id LIMIT \$4 OFFSET \$5`).
+		WithArgs("%user\\_%", "%user\\_%", "user", 5, 10).
+		WillReturnRows(rows)
 
-	rows := sqlmock.NewRows([]string{"username", "email"}).
-		AddRow("user1", "user1@example.com").
-		AddRow("user2", "user2@example.com")
-	mock.ExpectQuery(`SELECT \* FROM "users" WHERE role = \$1`).WithArgs("user").WillReturnRows(rows)
-
-	ctrl := controllers.NewAdminController(database.DB)
+	ctrl := controllers.NewAdminController(testContainer(gormDB))
 
 	app.Get("/admin/users", ctrl.GetAllUsers)
 
-	req := httptest.NewRequest("GET", "/admin/users", nil)
+	req := httptest.NewRequest("GET", "/admin/users?q=User_&role=user&page=3&per_page=5", nil)
 	req.Header.Set("Authorization", "Bearer valid_token")
 
 	resp, err := app.Test(req)
@@ -65,10 +71,13 @@ func TestGetAllUsers(t *testing.T) {
 
 	expectedResponse := models.GetAllUsersResponse{
 		Code: 200,
-		Message: []models.UserResponse{
-			{Username: "user1", Email: "user1@example.com"},
-			{Username: "user2", Email: "user2@example.com"},
+		Message: []models.UserSummary{
+			{ID: 11, Username: "user_11", Email: "user11@example.com", Role: "user", CreatedAt: created, OrderCount: 3},
+			{ID: 12, Username: "user_12", Email: "user12@example.com", Role: "user", CreatedAt: created, SuspendedAt: &created},
 		},
+		Page:    3,
+		PerPage: 5,
+		Total:   12,
 	}
 	assert.Equal(t, expectedResponse, response)
 
@@ -79,7 +88,7 @@ func TestGetAllUsers(t *testing.T) {
 
 func TestGetDashboard(t *testing.T) {
 	// Setup Fiber app and mock database
-	app := fiber.New()
+	app := newTestApp()
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("Failed to set up mock database: %s", err)
@@ -93,13 +102,11 @@ func TestGetDashboard(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to initialize GORM: %s", err)
 	}
-	database.SetDB(gormDB)
-
 	// Setup mock expectations
 	mock.ExpectQuery(`SELECT \* FROM "orders"`).WillReturnError(fmt.Errorf("database error"))
 
 	// Initialize admin controller with mock DB
-	ctrl := controllers.NewAdminController(database.DB)
+	ctrl := controllers.NewAdminController(testContainer(gormDB))
 
 	// Register endpoint handler
 	app.Get("/admin/dashboard", ctrl.GetDashboard)
@@ -126,7 +133,8 @@ func TestGetDashboard(t *testing.T) {
 	// Verify error response content
 	expectedError := models.ErrorResponse{
 		Code:    500,
-		Message: "Failed to fetch orders", // Adjust based on actual error handling
+		Error:   apierror.CodeInternal,
+		Message: "Failed to fetch orders",
 	}
 	assert.Equal(t, expectedError, response)
 
@@ -135,3 +143,89 @@ func TestGetDashboard(t *testing.T) {
 		t.Fatalf("Unfulfilled expectations: %s", err)
 	}
 }
+
+func TestCreateRationingRuleRequiresTarget(t *testing.T) {
+	app := newTestApp()
+
+	ctrl := controllers.NewRationingController(testContainer(nil))
+
+	app.Post("/admin/rationing-rules", ctrl.CreateRationingRule)
+
+	// Neither offer_id nor category is set, so the rule has nothing to apply to
+	body := strings.NewReader(`{"max_quantity": 2, "window_hours": 168}`)
+	req := httptest.NewRequest("POST", "/admin/rationing-rules", body)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var response models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode error response: %s", err)
+	}
+
+	assert.Equal(t, 400, response.Code)
+	assert.Equal(t, apierror.CodeValidationFailed, response.Error)
+	assert.Equal(t, "Invalid request", response.Message)
+	assert.Contains(t, response.Details, models.FieldError{
+		Field:   "offer_id",
+		Rule:    "required_without",
+		Message: "is required when category is not set",
+	})
+}
+
+func TestGetTradeBalance(t *testing.T) {
+	app := newTestApp()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to set up mock database: %s", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize GORM: %s", err)
+	}
+	rows := sqlmock.NewRows([]string{"community_id", "name", "ordered_amount", "delivered_amount", "settled_amount"}).
+		AddRow(1, "Puerto Madryn", 120.0, 80.0, 50.0).
+		AddRow(2, "Trelew", 0.0, 0.0, 0.0)
+	mock.ExpectQuery(`SELECT communities\.id AS community_id, communities\.name AS name`).WillReturnRows(rows)
+
+	ctrl := controllers.NewCommunityController(testContainer(gormDB))
+
+	app.Get("/admin/communities/trade-balance", ctrl.GetTradeBalance)
+
+	req := httptest.NewRequest("GET", "/admin/communities/trade-balance", nil)
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var response models.TradeBalanceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+
+	expectedResponse := models.TradeBalanceResponse{
+		Code: 200,
+		Message: []models.CommunityTradeBalance{
+			{CommunityID: 1, Name: "Puerto Madryn", OrderedAmount: 120, DeliveredAmount: 80, SettledAmount: 50, Balance: 70},
+			{CommunityID: 2, Name: "Trelew"},
+		},
+	}
+	assert.Equal(t, expectedResponse, response)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Unfulfilled expectations: %s", err)
+	}
+}
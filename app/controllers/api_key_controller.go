@@ -0,0 +1,220 @@
+// app/controllers/api_key_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyController lets users and admins create, list and revoke the API keys of machine clients
+type APIKeyController struct {
+	*container.Container
+}
+
+func NewAPIKeyController(app *container.Container) *APIKeyController {
+	return &APIKeyController{Container: app}
+}
+
+// summaries lists keys as their owners and admins see them
+func summaries(keys []models.APIKey) []models.APIKeySummary {
+	listed := make([]models.APIKeySummary, len(keys))
+	for i, key := range keys {
+		listed[i] = key.Summary()
+	}
+	return listed
+}
+
+// GetMyAPIKeys lists the API keys of the current user
+// @Summary List my API keys
+// @Description List the API keys of the authenticated user, newest first, revoked and expired ones included
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.APIKeysResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/api-keys [get]
+func (kc *APIKeyController) GetMyAPIKeys(c *fiber.Ctx) error {
+	user, err := kc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	keys, err := kc.APIKeyService.List(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal("Failed to fetch API keys", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIKeysResponse{
+		Code:    200,
+		Message: summaries(keys),
+	})
+}
+
+// CreateMyAPIKey creates an API key acting as the current user
+// @Summary Create an API key
+// @Description Create an API key acting as the authenticated user, sent by machine clients in the X-API-Key header. The key is only shown in this response. Scopes are read (GET requests), write (the other requests) and admin (the /admin routes, for admins only).
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.CreateAPIKeyRequest true "Name, scopes and optional expiry of the key"
+// @Success 201 {object} models.APIKeyCreatedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/api-keys [post]
+func (kc *APIKeyController) CreateMyAPIKey(c *fiber.Ctx) error {
+	var request models.CreateAPIKeyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	user, err := kc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	apiKey, key, err := kc.APIKeyService.Create(c.UserContext(), user, request)
+	if err != nil {
+		return serviceError(err, "Failed to create API key")
+	}
+	return created(c, apiKey, key)
+}
+
+// RevokeMyAPIKey revokes an API key of the current user
+// @Summary Revoke my API key
+// @Description Stop an API key of the authenticated user from working. It stays listed as revoked.
+// @Tags Auth
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/api-keys/{id} [delete]
+func (kc *APIKeyController) RevokeMyAPIKey(c *fiber.Ctx) error {
+	user, err := kc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+	return kc.revoke(c, user.ID)
+}
+
+// GetAPIKeys lists the API keys of every user, or of one
+// @Summary List API keys
+// @Description List the API keys of every user, or of the user given, newest first, revoked and expired ones included
+// @Tags Admin
+// @Produce json
+// @Param user_id query int false "Only the keys of this user"
+// @Success 200 {object} models.APIKeysResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (kc *APIKeyController) GetAPIKeys(c *fiber.Ctx) error {
+	userID := c.QueryInt("user_id", 0)
+	if userID < 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	keys, err := kc.APIKeyService.List(c.UserContext(), uint(userID))
+	if err != nil {
+		return apierror.Internal("Failed to fetch API keys", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIKeysResponse{
+		Code:    200,
+		Message: summaries(keys),
+	})
+}
+
+// CreateUserAPIKey creates an API key acting as a user
+// @Summary Create an API key for a user
+// @Description Create an API key acting as the given user, such as the account of another shelter's system or of the HPCPP integration scripts. The key is only shown in this response.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param data body models.CreateAPIKeyRequest true "Name, scopes and optional expiry of the key"
+// @Success 201 {object} models.APIKeyCreatedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/api-keys [post]
+func (kc *APIKeyController) CreateUserAPIKey(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	var request models.CreateAPIKeyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	apiKey, key, err := kc.APIKeyService.CreateFor(c.UserContext(), uint(userID), request)
+	if err != nil {
+		return serviceError(err, "Failed to create API key")
+	}
+	return created(c, apiKey, key)
+}
+
+// RevokeAPIKey revokes any API key
+// @Summary Revoke an API key
+// @Description Stop an API key from working, whoever it belongs to. It stays listed as revoked.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (kc *APIKeyController) RevokeAPIKey(c *fiber.Ctx) error {
+	return kc.revoke(c, 0)
+}
+
+// created answers with a new key, the only time the whole key is shown
+func created(c *fiber.Ctx, apiKey models.APIKey, key string) error {
+	return c.Status(fiber.StatusCreated).JSON(models.APIKeyCreatedResponse{
+		Code:    201,
+		Message: apiKey.Summary(),
+		Key:     key,
+	})
+}
+
+// revoke revokes the key in the path, which must belong to ownerID unless it is 0
+func (kc *APIKeyController) revoke(c *fiber.Ctx, ownerID uint) error {
+	keyID, err := c.ParamsInt("id")
+	if err != nil || keyID <= 0 {
+		return apierror.BadRequest("Invalid API key ID")
+	}
+
+	if _, err := kc.APIKeyService.Revoke(c.UserContext(), uint(keyID), ownerID); err != nil {
+		return serviceError(err, "Failed to revoke API key")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "API key revoked successfully",
+	})
+}
@@ -3,30 +3,29 @@
 package controllers
 
 import (
-	"fmt"
-	"os"
-	"strings"
+	"math"
+	"strconv"
 
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
-	"github.com/golang-jwt/jwt"
-	"gorm.io/gorm"
 
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/crypto/bcrypt"
 )
 
-const DefaultRole = "user"
-const AdminRole = "admin"
+const DefaultRole = services.RoleUser
+const AdminRole = services.RoleAdmin
 
 // AuthController handles authentication related requests
 type AuthController struct {
-	DB *gorm.DB
+	*container.Container
 }
 
-func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{DB: db}
+func NewAuthController(app *container.Container) *AuthController {
+	return &AuthController{Container: app}
 }
 
 // Register handles the registration of a new user
@@ -38,50 +37,23 @@ func NewAuthController(db *gorm.DB) *AuthController {
 // @Param data body models.RegisterRequest true "User data to register"
 // @Success 201 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /auth/register [post]
 func (ac *AuthController) Register(c *fiber.Ctx) error {
 	var requestData models.RegisterRequest
 	if err := c.BodyParser(&requestData); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "bad request",
-		})
+		return apierror.BadRequest("bad request")
 	}
 
-	db := database.GetDB()
-	// Validate username, email, and password
-	if err := utils.ValidateRegistrationRequest(requestData, db); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: err.Error(),
-		})
-	}
-
-	hashedPassword, err := utils.BcryptGenerateFromPassword([]byte(requestData.Password), bcrypt.DefaultCost)
+	// Validate the request, check the username and email are free and create the user
+	user, err := ac.UserService.Register(c.UserContext(), requestData)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to hash password",
-		})
-	}
-
-	// Create the user with default role
-	user := models.User{
-		Username: requestData.Username,
-		Email:    requestData.Email,
-		Password: string(hashedPassword),
-		Role:     DefaultRole, // Set the default role
+		return serviceError(err, "Failed to register user")
 	}
 
-	// Save the user to the database
-
-	if err := db.Create(&user).Error; err != nil {
-		fmt.Println("errorrrrrrrrrrrr", err)
-
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to register user",
-		})
+	// The account is usable right away, the user can ask for another email if this one fails
+	if err := ac.AccountService.SendVerification(c.UserContext(), user); err != nil {
+		middleware.RequestLogger(c).Warn("Failed to send verification email", "user_id", user.ID, "error", err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse{
@@ -99,33 +71,48 @@ func (ac *AuthController) Register(c *fiber.Ctx) error {
 // @Param data body models.LoginRequest true "User credentials for login"
 // @Success 200 {string} JWT "Authentication token"
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Router /auth/login [post]
 func (ac *AuthController) Login(c *fiber.Ctx) error {
 	var loginRequest models.LoginRequest
 	if err := c.BodyParser(&loginRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Bad request",
-		})
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, ac.Validator, loginRequest); err != nil {
+		return err
 	}
 
-	// Authenticate user (check username/password)
-	user, err := utils.AuthenticateUserFunc(loginRequest)
+	// Refuse logins for accounts and IPs with too many recent failures before checking the password
+	if wait := ac.LoginGuard.Check(loginRequest.Email, c.IP()); wait > 0 {
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return apierror.New(fiber.StatusTooManyRequests, apierror.CodeLoginLocked,
+			"Too many failed logins, try again in %d seconds", retryAfter)
+	}
+
+	// Authenticate user (check email/password)
+	user, err := ac.UserService.Authenticate(c.UserContext(), loginRequest)
+	if _, ok := services.AsRuleError(err); ok {
+		ac.LoginGuard.Fail(loginRequest.Email, c.IP())
+		return apierror.Unauthorized("Invalid credentials")
+	}
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Invalid credentials",
-		})
+		return apierror.Internal("Failed to authenticate user", err)
+	}
+	ac.LoginGuard.Succeed(loginRequest.Email)
+
+	// Suspensions are only reported after the password matched, so they reveal nothing to guessers
+	if user.SuspendedAt != nil {
+		return apierror.New(fiber.StatusForbidden, apierror.CodeAccountSuspended, "Account suspended")
 	}
 
 	// If authentication is successful, generate a JWT token
 	// Pass the user's role (e.g., "admin" or "regular") to the GenerateJWTToken function
-	token, err := utils.GenerateJWTTokenFunc(user.Email, user.Role)
+	token, err := utils.GenerateJWTTokenFunc(ac.Config.JWT, user)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to generate JWT token",
-		})
+		return apierror.Internal("Failed to generate JWT token", err)
 	}
 
 	// Set the token in the response header
@@ -145,59 +132,13 @@ func (ac *AuthController) Login(c *fiber.Ctx) error {
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /auth/offers [get]
 func (ac *AuthController) GetOffers(c *fiber.Ctx) error {
-
-	// Placeholder authentication check
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Unauthorized",
-		})
-	}
-
-	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
-	if jwtSecretKey == "" {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "JWT secret key not found",
-		})
-	}
-
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Invalid Authorization header format",
-		})
-	}
-
-	tokenString := parts[1]
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "Unexpected signing method")
-		}
-		return []byte(jwtSecretKey), nil
-	})
-
-	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Invalid or expired JWT",
-		})
-	}
-
-	// If the token is valid, fetch the offers from the database
-	db := database.GetDB()
-	offers := []models.Offer{}
-
-	if err := db.Find(&offers).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to fetch offers from the database",
-		})
+	// The route is authenticated by the auth middleware, with a JWT or an API key
+	offers, err := ac.CheckoutService.AvailableOffers(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch offers from the database", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.OfferResponse{
@@ -218,82 +159,29 @@ func (ac *AuthController) GetOffers(c *fiber.Ctx) error {
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /auth/checkout [post]
 func (ac *AuthController) Checkout(c *fiber.Ctx) error {
 	var checkoutRequest models.CheckoutRequest
 	if err := c.BodyParser(&checkoutRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Bad request",
-		})
+		return apierror.BadRequest("Bad request")
 	}
 
 	// Validate the request
-	if err := utils.ValidateCheckoutRequest(checkoutRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: err.Error(),
-		})
+	if err := validateRequest(c, ac.Validator, checkoutRequest); err != nil {
+		return err
 	}
 
-	db := database.GetDB()
-	tx := db.Begin() // Start a transaction
-
-	// Validate availability and calculate total amount
-	var totalAmount float64
-	var orderItems []models.OrderItem
-	for _, item := range checkoutRequest.Items {
-		var offer models.Offer
-		if err := tx.First(&offer, item.OfferID).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-				Code:    400,
-				Message: fmt.Sprintf("Offer with ID %d not found", item.OfferID),
-			})
-		}
-		if offer.Quantity < item.Quantity {
-			tx.Rollback()
-			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-				Code:    400,
-				Message: fmt.Sprintf("Not enough quantity for offer ID %d", item.OfferID),
-			})
-		}
-		subTotal := float64(item.Quantity) * offer.Price
-		totalAmount += subTotal
-		orderItems = append(orderItems, models.OrderItem{
-			OfferID:  item.OfferID,
-			Quantity: item.Quantity,
-			SubTotal: subTotal,
-		})
-	}
-
-	// Create the order
-	order := models.Order{
-		Status:      "processing",
-		OrderItems:  orderItems,
-		TotalAmount: totalAmount,
-	}
-	if err := tx.Create(&order).Error; err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to create order",
-		})
+	user, err := ac.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
 	}
 
-	// Update the stock of the offers
-	for _, item := range checkoutRequest.Items {
-		if err := tx.Model(&models.Offer{}).Where("id = ?", item.OfferID).Update("quantity", gorm.Expr("quantity - ?", item.Quantity)).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-				Code:    500,
-				Message: "Failed to update offer stock",
-			})
-		}
+	order, err := ac.CheckoutService.Checkout(c.UserContext(), user, checkoutRequest)
+	if err != nil {
+		return serviceError(err, "Failed to create order")
 	}
 
-	tx.Commit() // Commit the transaction
-
 	return c.Status(fiber.StatusOK).JSON(models.CheckoutResponse{
 		Code:    200,
 		Message: "Order created successfully",
@@ -310,75 +198,72 @@ func (ac *AuthController) Checkout(c *fiber.Ctx) error {
 // @Param id path int true "Order ID"
 // @Success 200 {object} models.OrderResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Router /auth/orders/{id} [get]
 func (ac *AuthController) GetOrderStatus(c *fiber.Ctx) error {
-	// Authentication check
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Unauthorized",
-		})
+	// Retrieve the order ID from the URL
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return apierror.BadRequest("Order ID is required")
 	}
 
-	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
-	if jwtSecretKey == "" {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "JWT secret key not found",
-		})
+	user, err := ac.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
 	}
 
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Invalid Authorization header format",
-		})
+	// Buyers only see their own orders, admins see every order
+	order, err := ac.OrderService.FindFor(c.UserContext(), user, uint(orderID))
+	if err != nil {
+		return serviceError(err, "Failed to fetch order from the database")
 	}
 
-	tokenString := parts[1]
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "Unexpected signing method")
-		}
-		return []byte(jwtSecretKey), nil
+	// Return the status of the order
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"code": 200,
+		"message": fiber.Map{
+			"status": order.Status,
+		},
 	})
+}
 
-	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
-			Code:    401,
-			Message: "Invalid or expired JWT",
-		})
+// CancelOrder handles the cancellation of an order by its buyer
+// @Summary Cancel an order
+// @Description Cancel an order that has not been shipped yet, returning its items to stock and refunding the credits paid
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.UpdateOrderStatusResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/orders/{id}/cancel [post]
+func (ac *AuthController) CancelOrder(c *fiber.Ctx) error {
+	user, err := ac.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
 	}
 
-	// Retrieve the order ID from the URL
-	orderID := c.Params("id")
-	if orderID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Code:    400,
-			Message: "Order ID is required",
-		})
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return apierror.BadRequest("Invalid order ID")
 	}
 
-	// Search for the order in the database
-	var order models.Order
-	db := database.GetDB()
-	if err := db.First(&order, orderID).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Code:    500,
-			Message: "Failed to fetch order from the database",
-		})
+	order, err := ac.OrderService.CancelByBuyer(c.UserContext(), user.ID, uint(orderID))
+	if err != nil {
+		return serviceError(err, "Failed to cancel order")
 	}
 
-	// Return the status of the order
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"code": 200,
-		"message": fiber.Map{
-			"status": order.Status,
-		},
+	return c.Status(fiber.StatusOK).JSON(models.UpdateOrderStatusResponse{
+		Code:    200,
+		Message: "Order cancelled successfully",
+		Status:  order.Status,
 	})
 }
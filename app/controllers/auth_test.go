@@ -10,11 +10,17 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -46,9 +52,21 @@ func setupMockDB(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to open gorm db: %s", err)
 	}
+}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "a6a6d01782e0cb082ad4b016a508d4a913c2556f38aa26303d0d00562e111aaa"
+	return &cfg
+}
 
-	database.SetDB(gormDB)
+func testContainer(db *gorm.DB) *container.Container {
+	return container.New(testConfig(), db)
+}
 
+// newTestApp creates a Fiber app that renders errors like the server does
+func newTestApp() *fiber.App {
+	return fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
 }
 
 func TestMain(m *testing.M) {
@@ -68,17 +86,19 @@ func TestGetOffers(t *testing.T) {
 	setupMockDB(t)
 	defer db.Close()
 
-	os.Setenv("JWT_SECRET_KEY", "a6a6d01782e0cb082ad4b016a508d4a913c2556f38aa26303d0d00562e111aaa")
-	defer os.Unsetenv("JWT_SECRET_KEY")
-
-	app := fiber.New()
+	app := newTestApp()
 
 	rows := sqlmock.NewRows([]string{"id", "name", "quantity", "price", "category"}).
 		AddRow(1, "Offer 1", 10, 20.5, "Category A").
 		AddRow(2, "Offer 2", 5, 15.75, "Category B")
 	mock.ExpectQuery(`SELECT \* FROM "offers"`).WillReturnRows(rows)
 
-	ctrl := controllers.NewAuthController(database.DB)
+	reservedRows := sqlmock.NewRows([]string{"offer_id", "reserved"}).
+		AddRow(2, 3)
+	mock.ExpectQuery(`SELECT reservation_items\.offer_id AS offer_id, SUM\(reservation_items\.quantity\) AS reserved FROM "reservation_items"`).
+		WillReturnRows(reservedRows)
+
+	ctrl := controllers.NewAuthController(testContainer(gormDB))
 
 	app.Get("/auth/offers", ctrl.GetOffers)
 
@@ -87,7 +107,7 @@ func TestGetOffers(t *testing.T) {
 		Role:  "user",
 	}
 
-	token, err := utils.GenerateJWTTokenFunc(user.Email, user.Role)
+	token, err := utils.GenerateJWTTokenFunc(testConfig().JWT, user)
 	if err != nil {
 		t.Fatalf("Failed to generate JWT token: %v", err)
 	}
@@ -109,20 +129,36 @@ func TestGetOffers(t *testing.T) {
 
 	expectedResponse := models.OfferResponse{
 		Code:    200,
-		Message: []models.Offer{{ID: 1, Name: "Offer 1", Quantity: 10, Price: 20.5, Category: "Category A"}, {ID: 2, Name: "Offer 2", Quantity: 5, Price: 15.75, Category: "Category B"}},
+		Message: []models.Offer{{ID: 1, Name: "Offer 1", Quantity: 10, Price: 20.5, Category: "Category A"}, {ID: 2, Name: "Offer 2", Quantity: 2, Price: 15.75, Category: "Category B"}},
 	}
 	assert.Equal(t, expectedResponse, response)
 }
 
-func TestLogin(t *testing.T) {
-	setupMockDB(t)
-	defer db.Close()
+// loginContainer wires the controllers around a memory store holding valid@example.com and the
+// suspended suspended@example.com, both with the password validpassword
+func loginContainer(t *testing.T) *container.Container {
+	store := repositories.NewMemoryStore()
+	hash, err := bcrypt.GenerateFromPassword([]byte("validpassword"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %s", err)
+	}
+	suspendedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, user := range []models.User{
+		{Username: "valid", Email: "valid@example.com", Password: string(hash), Role: "user"},
+		{Username: "suspended", Email: "suspended@example.com", Password: string(hash), Role: "user", SuspendedAt: &suspendedAt},
+	} {
+		if err := store.Users().Create(&user); err != nil {
+			t.Fatalf("Failed to create user: %s", err)
+		}
+	}
+	return container.WithStore(testConfig(), nil, clock.Real{}, store)
+}
 
+func TestLogin(t *testing.T) {
 	tests := []struct {
 		name           string
 		loginRequest   models.LoginRequest
-		mockAuthUser   func(models.LoginRequest) (models.User, error)
-		mockGenToken   func(string, string) (string, error)
+		mockGenToken   func(config.JWTConfig, models.User) (string, error)
 		expectedStatus int
 		expectedBody   interface{}
 	}{
@@ -132,13 +168,7 @@ func TestLogin(t *testing.T) {
 				Email:    "valid@example.com",
 				Password: "validpassword",
 			},
-			mockAuthUser: func(loginRequest models.LoginRequest) (models.User, error) {
-				return models.User{
-					Email: "valid@example.com",
-					Role:  "user",
-				}, nil
-			},
-			mockGenToken: func(email, role string) (string, error) {
+			mockGenToken: func(cfg config.JWTConfig, user models.User) (string, error) {
 				return "mockJWTToken", nil
 			},
 			expectedStatus: http.StatusOK,
@@ -149,39 +179,64 @@ func TestLogin(t *testing.T) {
 		{
 			name: "Invalid credentials",
 			loginRequest: models.LoginRequest{
-				Email:    "invalid@example.com",
+				Email:    "valid@example.com",
 				Password: "invalidpassword",
 			},
-			mockAuthUser: func(loginRequest models.LoginRequest) (models.User, error) {
-				return models.User{}, errors.New("invalid credentials")
+			mockGenToken: func(cfg config.JWTConfig, user models.User) (string, error) {
+				return "", nil
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody: models.ErrorResponse{
+				Code:    401,
+				Error:   apierror.CodeUnauthorized,
+				Message: "Invalid credentials",
+			},
+		},
+		{
+			name: "Unknown email",
+			loginRequest: models.LoginRequest{
+				Email:    "invalid@example.com",
+				Password: "validpassword",
 			},
-			mockGenToken: func(email, role string) (string, error) {
+			mockGenToken: func(cfg config.JWTConfig, user models.User) (string, error) {
 				return "", nil
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectedBody: models.ErrorResponse{
 				Code:    401,
+				Error:   apierror.CodeUnauthorized,
 				Message: "Invalid credentials",
 			},
 		},
+		{
+			name: "Suspended account",
+			loginRequest: models.LoginRequest{
+				Email:    "suspended@example.com",
+				Password: "validpassword",
+			},
+			mockGenToken: func(cfg config.JWTConfig, user models.User) (string, error) {
+				return "mockJWTToken", nil
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: models.ErrorResponse{
+				Code:    403,
+				Error:   apierror.CodeAccountSuspended,
+				Message: "Account suspended",
+			},
+		},
 		{
 			name: "Failed to generate JWT token",
 			loginRequest: models.LoginRequest{
 				Email:    "valid@example.com",
 				Password: "validpassword",
 			},
-			mockAuthUser: func(loginRequest models.LoginRequest) (models.User, error) {
-				return models.User{
-					Email: "valid@example.com",
-					Role:  "user",
-				}, nil
-			},
-			mockGenToken: func(email, role string) (string, error) {
+			mockGenToken: func(cfg config.JWTConfig, user models.User) (string, error) {
 				return "", errors.New("failed to generate JWT token")
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody: models.ErrorResponse{
 				Code:    500,
+				Error:   apierror.CodeInternal,
 				Message: "Failed to generate JWT token",
 			},
 		},
@@ -189,12 +244,13 @@ func TestLogin(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			utils.AuthenticateUserFunc = tt.mockAuthUser
+			generate := utils.GenerateJWTTokenFunc
+			defer func() { utils.GenerateJWTTokenFunc = generate }()
 			utils.GenerateJWTTokenFunc = tt.mockGenToken
 
-			app := fiber.New()
+			app := newTestApp()
 
-			ctrl := &controllers.AuthController{}
+			ctrl := controllers.NewAuthController(loginContainer(t))
 
 			app.Post("/auth/login", ctrl.Login)
 
@@ -233,16 +289,16 @@ func TestRegister(t *testing.T) {
 	setupMockDB(t)
 	defer db.Close()
 
-	app := fiber.New()
+	app := newTestApp()
 
-	ctrl := controllers.NewAuthController(database.DB)
+	ctrl := controllers.NewAuthController(testContainer(gormDB))
 
 	app.Post("/auth/register", ctrl.Register)
 
 	requestData := models.RegisterRequest{
 		Username: "testuser",
 		Email:    "test@example.com",
-		Password: "testpassword",
+		Password: "Gr33n-Valley!",
 	}
 
 	mockPassword := "$2a$10$mockpasswordmockpasswordmockpass"
@@ -265,7 +321,7 @@ func TestRegister(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`INSERT INTO "users" \("created_at","updated_at","deleted_at","username","email","password","role"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) RETURNING "id"`).
+	mock.ExpectQuery(`INSERT INTO "users" \("created_at","updated_at","deleted_at","username","email","password","role","email_verified_at","shelter","sector","delivery_location","suspended_at","token_version"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12,\$13\) RETURNING "id"`).
 		WithArgs(
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
@@ -274,9 +330,39 @@ func TestRegister(t *testing.T) {
 			requestData.Email,
 			mockPassword,
 			"user",
+			nil,
+			"",
+			"",
+			"",
+			nil,
+			0,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
+	// New users start with the configured credits, in the same transaction
+	mock.ExpectQuery(`INSERT INTO "wallets" \("created_at","updated_at","deleted_at","user_id","balance"\) VALUES \(\$1,\$2,\$3,\$4,\$5\) ON CONFLICT DO NOTHING RETURNING "id"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "wallets" SET "balance"=balance \+ \$1,"updated_at"=\$2 WHERE user_id = \$3 AND "wallets"\."deleted_at" IS NULL`).
+		WithArgs(testConfig().Wallet.StartingCredits, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "wallet_transactions"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, testConfig().Wallet.StartingCredits, models.TransactionGrant, nil, "Starting credits").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	mock.ExpectCommit()
+
+	// A verification token replaces any earlier one and is mailed to the new user
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "user_tokens" SET "used_at"=\$1,"updated_at"=\$2 WHERE \(user_id = \$3 AND purpose = \$4 AND used_at IS NULL\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, models.TokenEmailVerification).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "user_tokens"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, models.TokenEmailVerification, sqlmock.AnyArg(), requestData.Email, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
 	requestBody, _ := json.Marshal(requestData)
@@ -305,4 +391,8 @@ func TestRegister(t *testing.T) {
 	if response != expectedResponse {
 		t.Fatalf("Expected response %+v but got %+v", expectedResponse, response)
 	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("There were unfulfilled expectations: %s", err)
+	}
 }
@@ -0,0 +1,238 @@
+// app/controllers/community_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CommunityController handles trade partner communities and their representatives
+type CommunityController struct {
+	*container.Container
+}
+
+func NewCommunityController(app *container.Container) *CommunityController {
+	return &CommunityController{Container: app}
+}
+
+// communityID parses the :id route parameter
+func communityID(c *fiber.Ctx) (uint, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, apierror.NotFound("Community not found")
+	}
+	return uint(id), nil
+}
+
+// GetCommunities lists every trade partner community with its representatives
+// @Summary List communities
+// @Description Retrieve all trade partner communities and their representatives
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.CommunitiesResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities [get]
+func (cc *CommunityController) GetCommunities(c *fiber.Ctx) error {
+	communities, err := cc.CommunityService.List(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch communities", err)
+	}
+
+	summaries := make([]models.CommunitySummary, len(communities))
+	for i, community := range communities {
+		summaries[i] = community.Summary()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CommunitiesResponse{
+		Code:    200,
+		Message: summaries,
+	})
+}
+
+// CreateCommunity registers a new trade partner community
+// @Summary Create a community
+// @Description Register a nearby community the refuge trades with
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param data body models.CommunityRequest true "Community data"
+// @Success 201 {object} models.CommunityResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities [post]
+func (cc *CommunityController) CreateCommunity(c *fiber.Ctx) error {
+	var request models.CommunityRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, cc.Validator, request); err != nil {
+		return err
+	}
+
+	community, err := cc.CommunityService.Create(c.UserContext(), request)
+	if err != nil {
+		return serviceError(err, "Failed to create community")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CommunityResponse{
+		Code:    201,
+		Message: community.Summary(),
+	})
+}
+
+// AddRepresentative allows a user to place orders on behalf of a community
+// @Summary Add a community representative
+// @Description Allow a user to place trade orders on behalf of a community
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Community ID"
+// @Param data body models.RepresentativeRequest true "Representative data"
+// @Success 200 {object} models.CommunityResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities/{id}/representatives [post]
+func (cc *CommunityController) AddRepresentative(c *fiber.Ctx) error {
+	var request models.RepresentativeRequest
+	if err := c.BodyParser(&request); err != nil || request.UserID == 0 {
+		return apierror.BadRequest("Bad request")
+	}
+
+	id, err := communityID(c)
+	if err != nil {
+		return err
+	}
+
+	community, err := cc.CommunityService.AddRepresentative(c.UserContext(), id, request.UserID)
+	if err != nil {
+		return serviceError(err, "Failed to add representative")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CommunityResponse{
+		Code:    200,
+		Message: community.Summary(),
+	})
+}
+
+// RemoveRepresentative revokes a user's right to order on behalf of a community
+// @Summary Remove a community representative
+// @Description Revoke a user's right to place trade orders on behalf of a community
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Community ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities/{id}/representatives/{userId} [delete]
+func (cc *CommunityController) RemoveRepresentative(c *fiber.Ctx) error {
+	id, err := communityID(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := c.ParamsInt("userId")
+	if err != nil || userID <= 0 {
+		return apierror.BadRequest("Invalid user ID")
+	}
+
+	if err := cc.CommunityService.RemoveRepresentative(c.UserContext(), id, uint(userID)); err != nil {
+		return serviceError(err, "Failed to remove representative")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Representative removed successfully",
+	})
+}
+
+// RecordSettlement records goods or credits a community handed over to pay its trade debt
+// @Summary Record a community settlement
+// @Description Record a payment made by a community against what it owes
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Community ID"
+// @Param data body models.SettlementRequest true "Settlement data"
+// @Success 201 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities/{id}/settlements [post]
+func (cc *CommunityController) RecordSettlement(c *fiber.Ctx) error {
+	var request models.SettlementRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, cc.Validator, request); err != nil {
+		return err
+	}
+
+	id, err := communityID(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cc.CommunityService.RecordSettlement(c.UserContext(), id, request); err != nil {
+		return serviceError(err, "Failed to record settlement")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse{
+		Code:    201,
+		Message: "Settlement recorded successfully",
+	})
+}
+
+// GetTradeBalance reports what each community has ordered, received and still owes
+// @Summary Get trade balance per community
+// @Description Report ordered, delivered and settled amounts and the outstanding balance of every community
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.TradeBalanceResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/communities/trade-balance [get]
+func (cc *CommunityController) GetTradeBalance(c *fiber.Ctx) error {
+	balances, err := cc.CommunityService.TradeBalances(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to compute trade balance", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TradeBalanceResponse{
+		Code:    200,
+		Message: balances,
+	})
+}
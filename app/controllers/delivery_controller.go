@@ -0,0 +1,199 @@
+// app/controllers/delivery_controller.go
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeliveryController handles delivery slots and the delivery of orders
+type DeliveryController struct {
+	*container.Container
+}
+
+func NewDeliveryController(app *container.Container) *DeliveryController {
+	return &DeliveryController{Container: app}
+}
+
+// GetDeliverySlots lists the delivery slots that have not ended yet
+// @Summary List delivery slots
+// @Description Retrieve the upcoming delivery slots
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.DeliverySlotsResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/delivery-slots [get]
+func (dc *DeliveryController) GetDeliverySlots(c *fiber.Ctx) error {
+	slots, err := dc.DeliveryService.Slots(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch delivery slots", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.DeliverySlotsResponse{
+		Code:    200,
+		Message: slots,
+	})
+}
+
+// CreateDeliverySlot opens a new delivery slot
+// @Summary Create a delivery slot
+// @Description Open a time window in which couriers deliver orders
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param data body models.DeliverySlotRequest true "Delivery slot data"
+// @Success 201 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/delivery-slots [post]
+func (dc *DeliveryController) CreateDeliverySlot(c *fiber.Ctx) error {
+	var request models.DeliverySlotRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, dc.Validator, request); err != nil {
+		return err
+	}
+
+	if _, err := dc.DeliveryService.CreateSlot(c.UserContext(), request); err != nil {
+		return apierror.Internal("Failed to create delivery slot", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse{
+		Code:    201,
+		Message: "Delivery slot created successfully",
+	})
+}
+
+// AssignDelivery schedules the delivery of an order in a slot with a courier and a destination
+// @Summary Schedule the delivery of an order
+// @Description Assign a delivery slot, a courier and a destination to an order
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.AssignDeliveryRequest true "Delivery data"
+// @Success 200 {object} models.DeliveryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/orders/{id}/delivery [put]
+func (dc *DeliveryController) AssignDelivery(c *fiber.Ctx) error {
+	var request models.AssignDeliveryRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, dc.Validator, request); err != nil {
+		return err
+	}
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return apierror.NotFound("Order not found")
+	}
+
+	delivery, err := dc.DeliveryService.Assign(c.UserContext(), uint(orderID), request)
+	if err != nil {
+		return serviceError(err, "Failed to schedule delivery")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.DeliveryResponse{
+		Code:    200,
+		Message: delivery,
+	})
+}
+
+// GetOrderDelivery returns the delivery details of an order of the current user
+// @Summary Get the delivery of an order
+// @Description Retrieve when, where and by whom an order of the authenticated user will be delivered
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.DeliveryResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/orders/{id}/delivery [get]
+func (dc *DeliveryController) GetOrderDelivery(c *fiber.Ctx) error {
+	user, err := dc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil || orderID <= 0 {
+		return apierror.NotFound("Delivery not scheduled")
+	}
+
+	delivery, err := dc.DeliveryService.ForBuyer(c.UserContext(), user.ID, uint(orderID))
+	if err != nil {
+		return serviceError(err, "Failed to fetch delivery")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.DeliveryResponse{
+		Code:    200,
+		Message: delivery,
+	})
+}
+
+// GetCourierDeliveries lists the deliveries assigned to the current user for a day
+// @Summary List my deliveries
+// @Description Retrieve the deliveries assigned to the authenticated courier for a day (defaults to today)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param date query string false "Day to list, formatted as YYYY-MM-DD"
+// @Success 200 {object} models.DeliveriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/courier/deliveries [get]
+func (dc *DeliveryController) GetCourierDeliveries(c *fiber.Ctx) error {
+	user, err := dc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	day := dc.Clock.Now()
+	if date := c.Query("date"); date != "" {
+		day, err = time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return apierror.BadRequest("Invalid date, expected YYYY-MM-DD")
+		}
+	}
+
+	deliveries, err := dc.DeliveryService.ForCourier(c.UserContext(), user.ID, day)
+	if err != nil {
+		return apierror.Internal("Failed to fetch deliveries", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.DeliveriesResponse{
+		Code:    200,
+		Message: deliveries,
+	})
+}
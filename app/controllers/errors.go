@@ -0,0 +1,44 @@
+// app/controllers/errors.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ruleStatus is the HTTP status of each broken business rule, 400 when it is not listed
+var ruleStatus = map[string]int{
+	services.ReasonNotFound:        fiber.StatusNotFound,
+	services.ReasonConflict:        fiber.StatusConflict,
+	services.ReasonForbidden:       fiber.StatusForbidden,
+	services.ReasonAlreadyVerified: fiber.StatusConflict,
+}
+
+// validateRequest checks request against its validate tags and answers with every invalid field
+func validateRequest(c *fiber.Ctx, v *validation.Validator, request interface{}) error {
+	if err := v.Struct(c.UserContext(), request); err != nil {
+		return serviceError(err, "Failed to validate request")
+	}
+	return nil
+}
+
+// serviceError turns the error returned by a service into the API error to answer with.
+// Invalid requests list their invalid fields and broken business rules keep their reason
+// as error code; anything else is an internal error described by message.
+func serviceError(err error, message string) error {
+	if fieldErrs, ok := validation.AsErrors(err); ok {
+		return apierror.Validation("Invalid request", fieldErrs...)
+	}
+	ruleErr, ok := services.AsRuleError(err)
+	if !ok {
+		return apierror.Internal(message, err)
+	}
+	status, ok := ruleStatus[ruleErr.Reason]
+	if !ok {
+		status = fiber.StatusBadRequest
+	}
+	return apierror.New(status, ruleErr.Reason, "%s", ruleErr.Message)
+}
@@ -0,0 +1,119 @@
+// app/controllers/health_controller.go
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/version"
+	"github.com/gofiber/fiber/v2"
+)
+
+// pingTimeout bounds the database ping of the readiness probe
+const pingTimeout = 2 * time.Second
+
+// HealthController answers the liveness, readiness and version probes
+type HealthController struct {
+	*container.Container
+}
+
+func NewHealthController(app *container.Container) *HealthController {
+	return &HealthController{Container: app}
+}
+
+// Healthz reports that the process is alive
+// @Summary Liveness probe
+// @Description Report that the process is alive, without checking its dependencies
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Router /healthz [get]
+func (hc *HealthController) Healthz(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.HealthResponse{Status: "ok"})
+}
+
+// Readyz reports whether the API can serve requests
+// @Summary Readiness probe
+// @Description Check the database connection, the schema version and the age of the last supplies sync
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.HealthResponse
+// @Router /readyz [get]
+func (hc *HealthController) Readyz(c *fiber.Ctx) error {
+	checks := map[string]string{
+		"database":   hc.checkDatabase(),
+		"migrations": hc.checkMigrations(),
+		"supplies":   hc.checkSupplies(),
+	}
+
+	for _, result := range checks {
+		if result != "ok" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(models.HealthResponse{
+				Status: "unavailable",
+				Checks: checks,
+			})
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(models.HealthResponse{Status: "ok", Checks: checks})
+}
+
+// Version returns the build information of the running binary
+// @Summary Build version
+// @Description Return the commit and time the binary was built from, and the schema version it expects
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.VersionResponse
+// @Router /version [get]
+func (hc *HealthController) Version(c *fiber.Ctx) error {
+	latest, _ := database.LatestVersion()
+	return c.Status(fiber.StatusOK).JSON(models.VersionResponse{
+		Commit:    version.Commit,
+		BuildTime: version.BuildTime,
+		Schema:    latest,
+	})
+}
+
+func (hc *HealthController) checkDatabase() string {
+	sqlDB, err := hc.DB.DB()
+	if err != nil {
+		return err.Error()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+func (hc *HealthController) checkMigrations() string {
+	latest, err := database.LatestVersion()
+	if err != nil {
+		return err.Error()
+	}
+	current, err := database.SchemaVersion(hc.DB)
+	if err != nil {
+		return err.Error()
+	}
+	if current != latest {
+		return fmt.Sprintf("schema at version %d, expected %d", current, latest)
+	}
+	return "ok"
+}
+
+func (hc *HealthController) checkSupplies() string {
+	last := hc.SuppliesSync.LastSuccess()
+	if last.IsZero() {
+		return "supplies were never synced"
+	}
+	if age := hc.Clock.Now().Sub(last); age > hc.Config.Supplies.MaxAge {
+		return fmt.Sprintf("last supplies sync was %s ago", age.Round(time.Second))
+	}
+	return "ok"
+}
@@ -0,0 +1,62 @@
+// app/controllers/health_test.go
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthz(t *testing.T) {
+	app := newTestApp()
+	ctrl := controllers.NewHealthController(testContainer(nil))
+	app.Get("/healthz", ctrl.Healthz)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestReadyzBeforeFirstSuppliesSync(t *testing.T) {
+	setupMockDB(t)
+	defer db.Close()
+
+	latest, err := database.LatestVersion()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %s", err)
+	}
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(latest))
+
+	app := newTestApp()
+	ctrl := controllers.NewHealthController(testContainer(gormDB))
+	app.Get("/readyz", ctrl.Readyz)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var response models.HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	assert.Equal(t, "unavailable", response.Status)
+	assert.Equal(t, "ok", response.Checks["database"])
+	assert.Equal(t, "ok", response.Checks["migrations"])
+	assert.Equal(t, "supplies were never synced", response.Checks["supplies"])
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,11 @@
+// app/controllers/locals.go
+
+package controllers
+
+import "github.com/gofiber/fiber/v2"
+
+// currentUserID returns the ID of the caller stored in the locals by the auth middleware
+func currentUserID(c *fiber.Ctx) uint {
+	id, _ := c.Locals("user_id").(uint)
+	return id
+}
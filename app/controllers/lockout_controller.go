@@ -0,0 +1,94 @@
+// app/controllers/lockout_controller.go
+
+package controllers
+
+import (
+	"net/url"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/lockout"
+	"github.com/gofiber/fiber/v2"
+)
+
+// LockoutController lets admins see and lift the lockouts caused by failed logins
+type LockoutController struct {
+	*container.Container
+}
+
+func NewLockoutController(app *container.Container) *LockoutController {
+	return &LockoutController{Container: app}
+}
+
+// GetLockouts lists the accounts and IPs with recent failed logins
+// @Summary List login lockouts
+// @Description List the accounts and client IPs with recent failed logins, the locked ones first
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.LoginLockoutsResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/lockouts [get]
+func (lc *LockoutController) GetLockouts(c *fiber.Ctx) error {
+	now := lc.Clock.Now()
+	lockouts := []models.LoginLockout{}
+	for _, entry := range lc.LoginGuard.Lockouts() {
+		lockoutResponse := models.LoginLockout{
+			Kind:        entry.Kind,
+			Subject:     entry.Subject,
+			Failures:    entry.Failures,
+			Lockouts:    entry.Lockouts,
+			LastFailure: entry.LastFailure,
+		}
+		if entry.Locked(now) {
+			lockedUntil := entry.LockedUntil
+			lockoutResponse.LockedUntil = &lockedUntil
+		}
+		lockouts = append(lockouts, lockoutResponse)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.LoginLockoutsResponse{
+		Code:    200,
+		Message: lockouts,
+	})
+}
+
+// ClearLockout forgets the failed logins of an account or IP
+// @Summary Clear a login lockout
+// @Description Forget the failed logins and lockouts of an account (by email) or a client IP, letting it log in again
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param kind path string true "account or ip"
+// @Param subject path string true "Email of the account or client IP"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/lockouts/{kind}/{subject} [delete]
+func (lc *LockoutController) ClearLockout(c *fiber.Ctx) error {
+	kind := c.Params("kind")
+	if kind != lockout.KindAccount && kind != lockout.KindIP {
+		return apierror.BadRequest("Lockout kind must be account or ip")
+	}
+	subject, err := url.PathUnescape(c.Params("subject"))
+	if err != nil || subject == "" {
+		return apierror.BadRequest("Invalid lockout subject")
+	}
+
+	if !lc.LoginGuard.Clear(kind, subject) {
+		return apierror.New(fiber.StatusNotFound, apierror.CodeNotFound, "No failed logins recorded for %s %s", kind, subject)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Lockout cleared successfully",
+	})
+}
@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLockoutCanBeCleared(t *testing.T) {
+	app := newTestApp()
+	deps := loginContainer(t)
+	authController := controllers.NewAuthController(deps)
+	lockoutController := controllers.NewLockoutController(deps)
+	app.Post("/auth/login", authController.Login)
+	app.Get("/admin/lockouts", lockoutController.GetLockouts)
+	app.Delete("/admin/lockouts/:kind/:subject", lockoutController.ClearLockout)
+
+	login := func() *http.Response {
+		body, _ := json.Marshal(models.LoginRequest{Email: "mia@example.com", Password: "guess"})
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to perform request: %s", err)
+		}
+		return resp
+	}
+
+	for i := 0; i < deps.Config.Lockout.MaxAccountFailures; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login().StatusCode)
+	}
+
+	resp := login()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+	var errorResp models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	assert.Equal(t, apierror.CodeLoginLocked, errorResp.Error)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/admin/lockouts", nil))
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+	var lockouts models.LoginLockoutsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lockouts); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	if assert.Len(t, lockouts.Message, 2) {
+		assert.Equal(t, "mia@example.com", lockouts.Message[0].Subject)
+		assert.NotNil(t, lockouts.Message[0].LockedUntil)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("DELETE", "/admin/lockouts/account/mia@example.com", nil))
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, login().StatusCode)
+}
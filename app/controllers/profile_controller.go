@@ -0,0 +1,126 @@
+// app/controllers/profile_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProfileController lets users see, edit and delete their own account
+type ProfileController struct {
+	*container.Container
+}
+
+func NewProfileController(app *container.Container) *ProfileController {
+	return &ProfileController{Container: app}
+}
+
+// GetProfile returns the profile of the current user
+// @Summary Get my profile
+// @Description Retrieve the account of the authenticated user, including their shelter, sector and delivery location
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.ProfileResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/me [get]
+func (pc *ProfileController) GetProfile(c *fiber.Ctx) error {
+	user, err := pc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.ProfileResponse{
+		Code:    200,
+		Message: user.Profile(),
+	})
+}
+
+// UpdateProfile edits the profile of the current user
+// @Summary Edit my profile
+// @Description Change the username, email or display info of the authenticated user. Fields left out are kept. A new email has to be verified again, and since JWTs identify users by email the response carries a new token to use from then on.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} models.ProfileResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/me [patch]
+func (pc *ProfileController) UpdateProfile(c *fiber.Ctx) error {
+	var request models.UpdateProfileRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	user, err := pc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	updated, err := pc.UserService.UpdateProfile(c.UserContext(), user, request)
+	if err != nil {
+		return serviceError(err, "Failed to update profile")
+	}
+
+	response := models.ProfileResponse{Code: 200, Message: updated.Profile()}
+	if updated.Email != user.Email {
+		// The change is saved, the user can ask for another email if this one fails
+		if err := pc.AccountService.SendVerification(c.UserContext(), updated); err != nil {
+			middleware.RequestLogger(c).Warn("Failed to send verification email", "user_id", updated.ID, "error", err)
+		}
+		token, err := utils.GenerateJWTTokenFunc(pc.Config.JWT, updated)
+		if err != nil {
+			return apierror.Internal("Failed to generate JWT token", err)
+		}
+		response.Token = token
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// DeleteProfile deletes the account of the current user
+// @Summary Delete my account
+// @Description Delete the account of the authenticated user, who must confirm their password. Personal data is anonymized and the orders placed stay in the trade log.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.DeleteAccountRequest true "Current password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /auth/me [delete]
+func (pc *ProfileController) DeleteProfile(c *fiber.Ctx) error {
+	var request models.DeleteAccountRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+	if err := validateRequest(c, pc.Validator, request); err != nil {
+		return err
+	}
+
+	user, err := pc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	if err := pc.UserService.DeleteSelf(c.UserContext(), user, request.Password); err != nil {
+		return serviceError(err, "Failed to delete account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Account deleted successfully",
+	})
+}
@@ -0,0 +1,62 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProfile(t *testing.T) {
+	setupMockDB(t)
+	defer db.Close()
+
+	app := newTestApp()
+	profileController := controllers.NewProfileController(testContainer(gormDB))
+	app.Get("/auth/me", func(c *fiber.Ctx) error {
+		c.Locals("user_id", uint(7))
+		return c.Next()
+	}, profileController.GetProfile)
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE "users"\."id" = \$1 AND "users"\."deleted_at" IS NULL ORDER BY "users"\."id" LIMIT \$2`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "username", "email", "password", "role", "shelter", "sector", "delivery_location"}).
+			AddRow(7, created, "mia", "mia@example.com", "$2a$10$hash", "user", "Vault 7", "North", "Gate B"))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/auth/me", nil))
+	if err != nil {
+		t.Fatalf("Failed to perform request: %s", err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	raw, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(raw), "password")
+
+	var profile models.ProfileResponse
+	if err := json.Unmarshal(raw, &profile); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	assert.Equal(t, models.UserProfile{
+		ID:               7,
+		Username:         "mia",
+		Email:            "mia@example.com",
+		Role:             "user",
+		Shelter:          "Vault 7",
+		Sector:           "North",
+		DeliveryLocation: "Gate B",
+		CreatedAt:        created,
+	}, profile.Message)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,154 @@
+// app/controllers/rationing_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RationingController handles the management of rationing rules by admins
+type RationingController struct {
+	*container.Container
+}
+
+func NewRationingController(app *container.Container) *RationingController {
+	return &RationingController{Container: app}
+}
+
+// GetRationingRules lists every rationing rule
+// @Summary List rationing rules
+// @Description Retrieve all per-user purchase limits
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.RationingRulesResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/rationing-rules [get]
+func (rc *RationingController) GetRationingRules(c *fiber.Ctx) error {
+	rules, err := rc.RationingService.Rules(c.UserContext())
+	if err != nil {
+		return apierror.Internal("Failed to fetch rationing rules", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.RationingRulesResponse{
+		Code:    200,
+		Message: rules,
+	})
+}
+
+// CreateRationingRule creates a new rationing rule
+// @Summary Create a rationing rule
+// @Description Cap how many units of an offer or category a single user can buy in a rolling window
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param data body models.RationingRuleRequest true "Rationing rule data"
+// @Success 201 {object} models.RationingRuleResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/rationing-rules [post]
+func (rc *RationingController) CreateRationingRule(c *fiber.Ctx) error {
+	var request models.RationingRuleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, rc.Validator, request); err != nil {
+		return err
+	}
+
+	rule, err := rc.RationingService.Create(c.UserContext(), request)
+	if err != nil {
+		return serviceError(err, "Failed to create rationing rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.RationingRuleResponse{
+		Code:    201,
+		Message: rule,
+	})
+}
+
+// UpdateRationingRule replaces the limits of an existing rationing rule
+// @Summary Update a rationing rule
+// @Description Update the target, limit or window of a rationing rule
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Rationing rule ID"
+// @Param data body models.RationingRuleRequest true "Rationing rule data"
+// @Success 200 {object} models.RationingRuleResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/rationing-rules/{id} [put]
+func (rc *RationingController) UpdateRationingRule(c *fiber.Ctx) error {
+	var request models.RationingRuleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, rc.Validator, request); err != nil {
+		return err
+	}
+
+	ruleID, err := c.ParamsInt("id")
+	if err != nil || ruleID <= 0 {
+		return apierror.NotFound("Rationing rule not found")
+	}
+
+	rule, err := rc.RationingService.Update(c.UserContext(), uint(ruleID), request)
+	if err != nil {
+		return serviceError(err, "Failed to update rationing rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.RationingRuleResponse{
+		Code:    200,
+		Message: rule,
+	})
+}
+
+// DeleteRationingRule removes a rationing rule
+// @Summary Delete a rationing rule
+// @Description Delete a rationing rule by ID
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Rationing rule ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/rationing-rules/{id} [delete]
+func (rc *RationingController) DeleteRationingRule(c *fiber.Ctx) error {
+	ruleID, err := c.ParamsInt("id")
+	if err != nil || ruleID <= 0 {
+		return apierror.NotFound("Rationing rule not found")
+	}
+
+	if err := rc.RationingService.Delete(c.UserContext(), uint(ruleID)); err != nil {
+		return serviceError(err, "Failed to delete rationing rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Rationing rule deleted successfully",
+	})
+}
@@ -0,0 +1,133 @@
+// app/controllers/reservation_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReservationController handles stock reservations placed during checkout
+type ReservationController struct {
+	*container.Container
+}
+
+func NewReservationController(app *container.Container) *ReservationController {
+	return &ReservationController{Container: app}
+}
+
+// CreateReservation holds stock for the current user
+// @Summary Reserve stock
+// @Description Hold quantities of one or more offers for a limited time before checkout
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param data body models.ReservationRequest true "Items to reserve"
+// @Success 201 {object} models.ReservationResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/reservations [post]
+func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
+	var request models.ReservationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, rc.Validator, request); err != nil {
+		return err
+	}
+
+	user, err := rc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	reservation, err := rc.ReservationService.Create(c.UserContext(), user, request)
+	if err != nil {
+		return serviceError(err, "Failed to create reservation")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.ReservationResponse{
+		Code:    201,
+		Message: "Reservation created successfully",
+		Data:    reservation,
+	})
+}
+
+// ConfirmReservation converts an active reservation into an order
+// @Summary Confirm a reservation
+// @Description Convert an active reservation of the current user into an order
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} models.CheckoutResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/reservations/{id}/confirm [post]
+func (rc *ReservationController) ConfirmReservation(c *fiber.Ctx) error {
+	user, err := rc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	reservationID, err := c.ParamsInt("id")
+	if err != nil || reservationID <= 0 {
+		return apierror.NotFound("Reservation not found")
+	}
+
+	order, err := rc.ReservationService.Confirm(c.UserContext(), user, uint(reservationID))
+	if err != nil {
+		return serviceError(err, "Failed to confirm reservation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CheckoutResponse{
+		Code:    200,
+		Message: "Order created successfully",
+		OrderID: order.ID,
+	})
+}
+
+// ReleaseReservation cancels an active reservation before it expires
+// @Summary Release a reservation
+// @Description Release the stock held by an active reservation of the current user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/reservations/{id} [delete]
+func (rc *ReservationController) ReleaseReservation(c *fiber.Ctx) error {
+	user, err := rc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	reservationID, err := c.ParamsInt("id")
+	if err != nil || reservationID <= 0 {
+		return apierror.NotFound("Active reservation not found")
+	}
+
+	if err := rc.ReservationService.Release(c.UserContext(), user.ID, uint(reservationID)); err != nil {
+		return serviceError(err, "Failed to release reservation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Code:    200,
+		Message: "Reservation released successfully",
+	})
+}
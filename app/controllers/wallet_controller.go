@@ -0,0 +1,122 @@
+// app/controllers/wallet_controller.go
+
+package controllers
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WalletController handles user credit balances and the credit ledger
+type WalletController struct {
+	*container.Container
+}
+
+func NewWalletController(app *container.Container) *WalletController {
+	return &WalletController{Container: app}
+}
+
+// GetWallet returns the credit balance of the current user
+// @Summary Get wallet balance
+// @Description Retrieve the credit balance of the authenticated user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.WalletResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/wallet [get]
+func (wc *WalletController) GetWallet(c *fiber.Ctx) error {
+	user, err := wc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	balance, err := wc.WalletService.Balance(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal("Failed to fetch wallet", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.WalletResponse{
+		Code:    200,
+		Message: "Wallet fetched successfully",
+		Balance: balance,
+	})
+}
+
+// GetWalletTransactions returns the credit ledger of the current user
+// @Summary Get wallet history
+// @Description Retrieve the credit transactions of the authenticated user, newest first
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.WalletTransactionsResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /auth/wallet/transactions [get]
+func (wc *WalletController) GetWalletTransactions(c *fiber.Ctx) error {
+	user, err := wc.UserService.FindByID(c.UserContext(), currentUserID(c))
+	if err != nil {
+		return apierror.Unauthorized("Unauthorized")
+	}
+
+	transactions, err := wc.WalletService.Transactions(c.UserContext(), user.ID)
+	if err != nil {
+		return apierror.Internal("Failed to fetch wallet transactions", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.WalletTransactionsResponse{
+		Code:    200,
+		Message: transactions,
+	})
+}
+
+// GrantCredits credits a user's wallet for labour contributed to the refuge
+// @Summary Grant credits to a user
+// @Description Add credits to the wallet of a user, e.g. as payment for labour
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param data body models.GrantCreditsRequest true "Credits to grant"
+// @Success 200 {object} models.WalletResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /admin/users/{id}/credits [post]
+func (wc *WalletController) GrantCredits(c *fiber.Ctx) error {
+	var request models.GrantCreditsRequest
+	if err := c.BodyParser(&request); err != nil {
+		return apierror.BadRequest("Bad request")
+	}
+
+	if err := validateRequest(c, wc.Validator, request); err != nil {
+		return err
+	}
+
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return apierror.NotFound("User not found")
+	}
+
+	balance, err := wc.WalletService.Grant(c.UserContext(), uint(userID), request)
+	if err != nil {
+		return serviceError(err, "Failed to grant credits")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.WalletResponse{
+		Code:    200,
+		Message: "Credits granted successfully",
+		Balance: balance,
+	})
+}
@@ -0,0 +1,108 @@
+// app/models/api_key_model.go
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scopes an API key can be granted
+const (
+	ScopeRead  = "read"  // GET requests
+	ScopeWrite = "write" // Every other request, such as checkouts
+	ScopeAdmin = "admin" // The /admin routes, only for the keys of admins
+)
+
+// APIKeyPrefix starts every API key, so keys pasted where they should not be are easy to spot
+const APIKeyPrefix = "nwk_"
+
+// APIKey model lets a machine client act as a user without their password. The key is shown once
+// when it is created; only its SHA-256 hash and its prefix, which tells keys apart in listings, are stored.
+type APIKey struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"not null;index"`      // User the key acts as
+	Name       string     `json:"name" gorm:"not null"`               // What the key is for, e.g. "HPCPP sync"
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;not null"` // First characters of the key
+	KeyHash    string     `json:"-" gorm:"not null"`                  // Hex SHA-256 of the whole key
+	Scopes     string     `json:"scopes" gorm:"not null"`             // Comma-separated scopes
+	ExpiresAt  *time.Time `json:"expires_at"`                         // The key is rejected after this instant, never if nil
+	LastUsedAt *time.Time `json:"last_used_at"`                       // Updated at most once a minute
+	LastUsedIP string     `json:"last_used_ip" gorm:"not null"`       // Client IP of the last use
+	RevokedAt  *time.Time `json:"revoked_at"`                         // Set once the key is revoked
+}
+
+// ScopeList returns the scopes of the key
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope reports whether the key was granted scope
+func (k APIKey) HasScope(scope string) bool {
+	for _, granted := range k.ScopeList() {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Usable reports whether the key is neither revoked nor expired at now
+func (k APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Summary returns the key as its owner and admins list it, without its hash
+func (k APIKey) Summary() APIKeySummary {
+	return APIKeySummary{
+		ID:         k.ID,
+		UserID:     k.UserID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		LastUsedIP: k.LastUsedIP,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+// APIKeySummary is an API key as listed to its owner and admins
+type APIKeySummary struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// CreateAPIKeyRequest defines the structure of the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,unique,dive,oneof=read write admin"`
+	ExpiresAt *time.Time `json:"expires_at"` // Never expires when left out
+}
+
+// APIKeyCreatedResponse defines the structure of the response for creating an API key
+type APIKeyCreatedResponse struct {
+	Code    int           `json:"code"`
+	Message APIKeySummary `json:"message"`
+	Key     string        `json:"key"` // The whole key, which is not shown again
+}
+
+// APIKeysResponse defines the structure of the response for listing API keys
+type APIKeysResponse struct {
+	Code    int             `json:"code"`
+	Message []APIKeySummary `json:"message"`
+}
@@ -2,15 +2,37 @@
 
 package models
 
-// LoginRequest represents the request body for the login endpoint
+// RegisterRequest represents the request body for the register endpoint
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required,max=50,unique_username"`
+	Email    string `json:"email" validate:"required,email,max=255,unique_email"`
+	Password string `json:"password" validate:"required,password"`
 }
 
 // LoginRequest defines the structure of the request body for user login
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ChangePasswordRequest defines the structure of the request body to change the password of the current user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// ForgotPasswordRequest defines the structure of the request body to mail a password reset token
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest defines the structure of the request body to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// VerifyEmailRequest defines the structure of the request body to verify an email with the token mailed to it
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
 }
@@ -0,0 +1,104 @@
+// app/models/community_model.go
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentOnAccount marks community orders that are owed by the community instead of paid with credits
+const PaymentOnAccount = "on_account"
+
+// Community model represents a nearby community the refuge trades with
+type Community struct {
+	gorm.Model             // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	Name            string `json:"name" gorm:"uniqueIndex;not null"`             // Unique name of the community
+	Location        string `json:"location"`                                     // Where the community is settled
+	Representatives []User `json:"-" gorm:"many2many:community_representatives"` // Users allowed to order on its behalf
+}
+
+// Representative is a user allowed to order on behalf of a community, without their password
+type Representative struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// CommunitySummary is a community as admins list it, with its representatives
+type CommunitySummary struct {
+	ID              uint             `json:"id"`
+	Name            string           `json:"name"`
+	Location        string           `json:"location"`
+	CreatedAt       time.Time        `json:"created_at"`
+	Representatives []Representative `json:"representatives"`
+}
+
+// Summary returns the community as admins list it, without the passwords of its representatives
+func (c Community) Summary() CommunitySummary {
+	summary := CommunitySummary{
+		ID:              c.ID,
+		Name:            c.Name,
+		Location:        c.Location,
+		CreatedAt:       c.CreatedAt,
+		Representatives: make([]Representative, len(c.Representatives)),
+	}
+	for i, user := range c.Representatives {
+		summary.Representatives[i] = Representative{ID: user.ID, Username: user.Username, Email: user.Email}
+	}
+	return summary
+}
+
+// CommunitySettlement model records goods or credits a community handed over to pay its trade debt
+type CommunitySettlement struct {
+	gorm.Model
+	CommunityID uint    `json:"community_id" gorm:"not null;index"` // Foreign key to communities table
+	Amount      float64 `json:"amount" gorm:"not null"`             // Value settled
+	Description string  `json:"description"`                        // What was delivered
+}
+
+// CommunityRequest defines the structure of the request to create a community
+type CommunityRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Location string `json:"location" validate:"max=255"`
+}
+
+// RepresentativeRequest defines the structure of the request to add a representative to a community
+type RepresentativeRequest struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+// SettlementRequest defines the structure of the request to record a community settlement
+type SettlementRequest struct {
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Description string  `json:"description" validate:"required,max=255"`
+}
+
+// CommunityResponse defines the structure of the response for a single community
+type CommunityResponse struct {
+	Code    int              `json:"code"`
+	Message CommunitySummary `json:"message"`
+}
+
+// CommunitiesResponse defines the structure of the response for listing communities
+type CommunitiesResponse struct {
+	Code    int                `json:"code"`
+	Message []CommunitySummary `json:"message"`
+}
+
+// CommunityTradeBalance defines the trade position of a single community
+type CommunityTradeBalance struct {
+	CommunityID     uint    `json:"community_id"`
+	Name            string  `json:"name"`
+	OrderedAmount   float64 `json:"ordered_amount"`   // Value of every non-cancelled order placed by the community
+	DeliveredAmount float64 `json:"delivered_amount"` // Value of the orders already delivered to the community
+	SettledAmount   float64 `json:"settled_amount"`   // Value the community has paid back
+	Balance         float64 `json:"balance"`          // What the community still owes (ordered - settled)
+}
+
+// TradeBalanceResponse defines the structure of the response for the trade balance report
+type TradeBalanceResponse struct {
+	Code    int                     `json:"code"`
+	Message []CommunityTradeBalance `json:"message"`
+}
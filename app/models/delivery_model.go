@@ -0,0 +1,62 @@
+// app/models/delivery_model.go
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeliverySlot model represents a time window in which couriers deliver orders
+type DeliverySlot struct {
+	gorm.Model           // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null;index"` // Beginning of the window
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`         // End of the window
+	Capacity   int       `json:"capacity" gorm:"not null"`        // Maximum number of deliveries in the window
+}
+
+// Delivery model holds when, where and by whom an order is delivered
+type Delivery struct {
+	gorm.Model
+	OrderID     uint         `json:"order_id" gorm:"uniqueIndex;not null"` // Foreign key to orders table
+	SlotID      uint         `json:"slot_id" gorm:"not null;index"`        // Foreign key to delivery_slots table
+	Slot        DeliverySlot `json:"slot" gorm:"foreignKey:SlotID"`        // Relation to the delivery slot
+	CourierID   uint         `json:"courier_id" gorm:"not null;index"`     // User in charge of the delivery
+	Destination string       `json:"destination" gorm:"not null"`          // Where the order has to be delivered
+	Notes       string       `json:"notes"`                                // Route or handover instructions
+	DeliveredAt *time.Time   `json:"delivered_at"`                         // Moment the order was marked as delivered
+}
+
+// DeliverySlotRequest defines the structure of the request to create a delivery slot
+type DeliverySlotRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Capacity int       `json:"capacity" validate:"required,min=1"`
+}
+
+// AssignDeliveryRequest defines the structure of the request to schedule the delivery of an order
+type AssignDeliveryRequest struct {
+	SlotID      uint   `json:"slot_id" validate:"required"`
+	CourierID   uint   `json:"courier_id" validate:"required"`
+	Destination string `json:"destination" validate:"required,max=255"`
+	Notes       string `json:"notes" validate:"max=500"`
+}
+
+// DeliverySlotsResponse defines the structure of the response for listing delivery slots
+type DeliverySlotsResponse struct {
+	Code    int            `json:"code"`
+	Message []DeliverySlot `json:"message"`
+}
+
+// DeliveryResponse defines the structure of the response for a single delivery
+type DeliveryResponse struct {
+	Code    int      `json:"code"`
+	Message Delivery `json:"message"`
+}
+
+// DeliveriesResponse defines the structure of the response for listing deliveries
+type DeliveriesResponse struct {
+	Code    int        `json:"code"`
+	Message []Delivery `json:"message"`
+}
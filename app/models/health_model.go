@@ -0,0 +1,16 @@
+// app/models/health_model.go
+
+package models
+
+// HealthResponse defines the structure of the response for the health and readiness endpoints
+type HealthResponse struct {
+	Status string            `json:"status"`           // "ok" or "unavailable"
+	Checks map[string]string `json:"checks,omitempty"` // Result of every readiness check by name
+}
+
+// VersionResponse defines the structure of the response for the version endpoint
+type VersionResponse struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Schema    int    `json:"schema_version"` // Migration version the binary expects
+}
@@ -6,12 +6,31 @@ import "gorm.io/gorm"
 
 // Order model represents an order in the system
 type Order struct {
-	gorm.Model              // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
-	Status      string      `json:"status" gorm:"not null"` // Status of the order (e.g., "processing", "completed")
-	OrderItems  []OrderItem `gorm:"foreignKey:OrderID"`     // Relation to order items
-	TotalAmount float64     `json:"total_amount"`           // Total amount of the order
+	gorm.Model                // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	UserID        uint        `json:"user_id" gorm:"index"`      // Buyer that placed the order
+	CommunityID   *uint       `json:"community_id" gorm:"index"` // Community the order was placed on behalf of, if any
+	Status        OrderStatus `json:"status" gorm:"not null"`    // Where the order is in its lifecycle
+	OrderItems    []OrderItem `gorm:"foreignKey:OrderID"`        // Relation to order items
+	TotalAmount   float64     `json:"total_amount"`              // Total amount of the order
+	PaymentStatus string      `json:"payment_status"`            // Payment status of the order (e.g., "paid", "refunded")
 }
 
+const (
+	PaymentPaid     = "paid"
+	PaymentRefunded = "refunded"
+)
+
+// OrderStatus is where an order is in its lifecycle
+type OrderStatus string
+
+const (
+	OrderProcessing OrderStatus = "processing"
+	OrderPreparing  OrderStatus = "preparing"
+	OrderShipped    OrderStatus = "shipped"
+	OrderDelivered  OrderStatus = "delivered"
+	OrderCancelled  OrderStatus = "cancelled"
+)
+
 // OrderItem model represents an item in an order
 type OrderItem struct {
 	gorm.Model
@@ -24,7 +43,8 @@ type OrderItem struct {
 
 // CheckoutRequest defines the structure of the request for the Checkout endpoint
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items" validate:"required"`
+	Items       []CheckoutItem `json:"items" validate:"required,min=1,unique=OfferID,dive"`
+	CommunityID *uint          `json:"community_id"` // Place the order on behalf of a community the user represents
 }
 
 // CheckoutItem defines the structure of each item in the CheckoutRequest
@@ -0,0 +1,36 @@
+// app/models/rationing_model.go
+
+package models
+
+import "gorm.io/gorm"
+
+// RationingRule model caps how much of an offer or category a single user can buy in a rolling window
+type RationingRule struct {
+	gorm.Model         // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	OfferID     *uint  `json:"offer_id" gorm:"index"`                  // Offer the rule applies to (mutually exclusive with Category)
+	Category    string `json:"category" gorm:"type:varchar(50);index"` // Category the rule applies to (mutually exclusive with OfferID)
+	MaxQuantity int    `json:"max_quantity" gorm:"not null"`           // Maximum units per user inside the window
+	WindowHours int    `json:"window_hours" gorm:"not null"`           // Length of the rolling window in hours
+	Description string `json:"description"`                            // Free text shown to buyers when the limit is hit
+}
+
+// RationingRuleRequest defines the structure of the request to create or update a rationing rule
+type RationingRuleRequest struct {
+	OfferID     *uint  `json:"offer_id" validate:"required_without=Category,excluded_with=Category"`
+	Category    string `json:"category" validate:"required_without=OfferID,omitempty,oneof=food drink medicine"`
+	MaxQuantity int    `json:"max_quantity" validate:"required,min=1"`
+	WindowHours int    `json:"window_hours" validate:"required,min=1"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// RationingRuleResponse defines the structure of the response for a single rationing rule
+type RationingRuleResponse struct {
+	Code    int           `json:"code"`
+	Message RationingRule `json:"message"`
+}
+
+// RationingRulesResponse defines the structure of the response for listing rationing rules
+type RationingRulesResponse struct {
+	Code    int             `json:"code"`
+	Message []RationingRule `json:"message"`
+}
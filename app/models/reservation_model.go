@@ -0,0 +1,46 @@
+// app/models/reservation_model.go
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation model represents a temporary hold of stock placed by a buyer before paying
+type Reservation struct {
+	gorm.Model                   // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	UserID     uint              `json:"user_id" gorm:"not null;index"`         // Buyer that owns the hold
+	Status     string            `json:"status" gorm:"not null;index"`          // Status of the hold (e.g., "active", "confirmed")
+	ExpiresAt  time.Time         `json:"expires_at" gorm:"not null;index"`      // Moment after which the hold no longer counts
+	OrderID    *uint             `json:"order_id"`                              // Order created when the hold was confirmed
+	Items      []ReservationItem `json:"items" gorm:"foreignKey:ReservationID"` // Relation to reserved items
+}
+
+// ReservationItem model represents the quantity of an offer held by a reservation
+type ReservationItem struct {
+	gorm.Model
+	ReservationID uint `json:"reservation_id" gorm:"not null;index"` // Foreign key to reservations table
+	OfferID       uint `json:"offer_id" gorm:"not null;index"`       // Foreign key to offers table
+	Quantity      int  `json:"quantity" gorm:"not null"`             // Quantity held
+}
+
+// ReservationRequest defines the structure of the request for the CreateReservation endpoint
+type ReservationRequest struct {
+	Items []CheckoutItem `json:"items" validate:"required,min=1,unique=OfferID,dive"`
+}
+
+// ReservationResponse defines the structure of the response for the reservation endpoints
+type ReservationResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    Reservation `json:"reservation"`
+}
@@ -2,12 +2,36 @@
 
 package models
 
-// ErrorResponse defines the structure of an error response
+import "time"
+
+// ErrorResponse defines the structure of every error response
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code      int          `json:"code"`                 // HTTP status
+	Error     string       `json:"error"`                // Machine-readable error code, e.g. validation_failed
+	Message   string       `json:"message"`              // Human-readable description
+	Details   []FieldError `json:"details,omitempty"`    // The fields that failed validation
+	RequestID string       `json:"request_id,omitempty"` // Matches the X-Request-ID header and the logs
+}
+
+// FieldError describes why one field of a request was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
 }
 
+// ProblemResponse is the RFC 7807 rendering of ErrorResponse, sent when the client accepts application/problem+json
+type ProblemResponse struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance"`
+	Code      string       `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
 // SuccessResponse defines la estructura de una respuesta exitosa
 type SuccessResponse struct {
 	Code    int    `json:"code"`
@@ -16,7 +40,7 @@ type SuccessResponse struct {
 
 // DashboardResponse defines the structure of the response for the dashboard endpoint
 type DashboardResponse struct {
-	Status  string           `json:"status"`
+	Code    int              `json:"code"`
 	Message string           `json:"message"`
 	Orders  []OrderDashboard `json:"orders"`
 }
@@ -24,7 +48,7 @@ type DashboardResponse struct {
 // OrderDashboard defines the structure for each order's details in the dashboard response
 type OrderDashboard struct {
 	ID          uint               `json:"id"`
-	Status      string             `json:"status"`
+	Status      OrderStatus        `json:"status"`
 	TotalAmount float64            `json:"total_amount"`
 	Items       []OrderItemDetails `json:"items"`
 }
@@ -38,26 +62,51 @@ type OrderItemDetails struct {
 
 // UpdateOrderStatusResponse defines the structure of the response for updating the order status
 type UpdateOrderStatusResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Status  string `json:"status"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Status  OrderStatus `json:"status"`
 }
 
 // UpdateOrderStatusRequest defines the structure of the request to update the order status
 type UpdateOrderStatusRequest struct {
-	Status string `json:"status" validate:"required,oneof=preparing processing shipped delivered"`
+	Status OrderStatus `json:"status" validate:"required,oneof=preparing processing shipped delivered cancelled"`
 }
 
-// UserResponse represents the structure of the response for getting a user
-type UserResponse struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
+// GetAllUsersResponse defines the structure of the response for listing users one page at a time
+type GetAllUsersResponse struct {
+	Code    int           `json:"code"`
+	Message []UserSummary `json:"message"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+	Total   int64         `json:"total"` // Users matching the filter across every page
 }
 
-// GetAllUsersResponse defines the structure of the response for getting all users
-type GetAllUsersResponse struct {
+// UserDetailResponse defines the structure of the response for getting a single user
+type UserDetailResponse struct {
+	Code    int        `json:"code"`
+	Message UserDetail `json:"message"`
+}
+
+// IdentityCollisionsResponse defines the structure of the response for listing identity collisions
+type IdentityCollisionsResponse struct {
+	Code    int                 `json:"code"`
+	Message []IdentityCollision `json:"message"`
+}
+
+// LoginLockout describes the failed logins of an account or a client IP
+type LoginLockout struct {
+	Kind        string     `json:"kind"`         // "account" or "ip"
+	Subject     string     `json:"subject"`      // Email of the account or client IP
+	Failures    int        `json:"failures"`     // Failed logins since the last lockout
+	Lockouts    int        `json:"lockouts"`     // Lockouts in a row
+	LastFailure time.Time  `json:"last_failure"` // When the last failed login happened
+	LockedUntil *time.Time `json:"locked_until"` // Set while logins are refused
+}
+
+// LoginLockoutsResponse defines the structure of the response for listing login lockouts
+type LoginLockoutsResponse struct {
 	Code    int            `json:"code"`
-	Message []UserResponse `json:"message"`
+	Message []LoginLockout `json:"message"`
 }
 
 // DeleteUserResponse defines the structure of the response for deleting a user
@@ -0,0 +1,26 @@
+// app/models/token_model.go
+
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+)
+
+// UserToken model is a single-use token mailed to a user to reset their password or verify their email.
+// Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index"` // Owner of the token
+	Purpose   string     `json:"purpose" gorm:"not null"`       // What the token allows (e.g., "password_reset")
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"` // Hex SHA-256 of the token sent by mail
+	Email     string     `json:"email" gorm:"not null"`         // Address the token was mailed to
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`    // The token is rejected after this instant
+	UsedAt    *time.Time `json:"used_at"`                       // When the token was redeemed or revoked
+}
@@ -2,13 +2,174 @@
 
 package models
 
-import "gorm.io/gorm"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User model represents a user in the system
 type User struct {
-	gorm.Model        // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
-	Username   string `json:"username" gorm:"uniqueIndex;not null"` // Unique username, cannot be null
-	Email      string `json:"email" gorm:"uniqueIndex;not null"`    // Unique email, cannot be null
-	Password   string `json:"password" gorm:"not null"`             // Password, cannot be null
-	Role       string `json:"role" gorm:"not null"`                 // Role of the user (e.g., "admin" or "regular")
+	gorm.Model // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	// Usernames and emails are unique among the users not deleted, so deleted accounts do not hold on to them
+	Username string `json:"username" gorm:"uniqueIndex:idx_users_username,where:deleted_at IS NULL;not null"`
+	Email    string `json:"email" gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null"`
+	Password string `json:"password" gorm:"not null"` // Password, cannot be null
+	Role     string `json:"role" gorm:"not null"`     // Role of the user (e.g., "admin" or "regular")
+	// When the user proved they own Email, nil until then or after changing it
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Shelter          string     `json:"shelter" gorm:"not null"`           // Shelter the user lives in
+	Sector           string     `json:"sector" gorm:"not null"`            // Sector of the shelter
+	DeliveryLocation string     `json:"delivery_location" gorm:"not null"` // Where orders are delivered by default
+	SuspendedAt      *time.Time `json:"suspended_at"`                      // Set while an admin has suspended the account
+	// Signed into the JWTs of the user, which stop being accepted once it is incremented
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
+}
+
+// AnonymizedEmailDomain is the domain of the placeholder emails of anonymized users, reserved so it never delivers
+const AnonymizedEmailDomain = "deleted.invalid"
+
+// Anonymized returns the user with its personal data replaced by placeholders derived from its ID,
+// so the orders it placed stay in the trade log without saying who placed them
+func (u User) Anonymized() User {
+	name := fmt.Sprintf("deleted-user-%d", u.ID)
+	u.Username = name
+	u.Email = name + "@" + AnonymizedEmailDomain
+	u.Password = "" // Matches no password, so nobody can log in
+	u.EmailVerifiedAt = nil
+	u.Shelter, u.Sector, u.DeliveryLocation = "", "", ""
+	return u
+}
+
+// IsAnonymized reports whether the personal data of the user has been wiped, which cannot be undone
+func (u User) IsAnonymized() bool {
+	return strings.HasSuffix(u.Email, "@"+AnonymizedEmailDomain)
+}
+
+// Summary returns the user as admins list them, without its order count
+func (u User) Summary() UserSummary {
+	summary := UserSummary{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Role:        u.Role,
+		CreatedAt:   u.CreatedAt,
+		SuspendedAt: u.SuspendedAt,
+	}
+	if u.DeletedAt.Valid {
+		summary.DeletedAt = &u.DeletedAt.Time
+	}
+	return summary
+}
+
+// UserProfile is what a user sees and edits of their own account
+type UserProfile struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Role             string     `json:"role"`
+	Shelter          string     `json:"shelter"`
+	Sector           string     `json:"sector"`
+	DeliveryLocation string     `json:"delivery_location"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Profile returns the profile of the user, leaving out the password hash
+func (u User) Profile() UserProfile {
+	return UserProfile{
+		ID:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		EmailVerifiedAt:  u.EmailVerifiedAt,
+		Role:             u.Role,
+		Shelter:          u.Shelter,
+		Sector:           u.Sector,
+		DeliveryLocation: u.DeliveryLocation,
+		CreatedAt:        u.CreatedAt,
+	}
+}
+
+// UserSummary is a user as admins list them
+type UserSummary struct {
+	ID          uint       `json:"id"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	SuspendedAt *time.Time `json:"suspended_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`  // Set while the user can still be restored or once anonymized
+	OrderCount  int64      `json:"order_count"` // Orders placed by the user
+}
+
+// UserPage is one page of the users matching a UserFilter
+type UserPage struct {
+	Users   []UserSummary
+	Page    int
+	PerPage int
+	Total   int64 // Users matching the filter across every page
+}
+
+// UserDetail is a user as admins inspect them, with their order history
+type UserDetail struct {
+	UserSummary
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Shelter          string     `json:"shelter"`
+	Sector           string     `json:"sector"`
+	DeliveryLocation string     `json:"delivery_location"`
+	Orders           []Order    `json:"orders"` // Newest first
+}
+
+// IdentityCollision groups the users whose usernames or emails are the same once normalized,
+// for an admin to sort out. The user holding the normalized form, if any, comes first.
+type IdentityCollision struct {
+	Field      string        `json:"field"`      // "username" or "email"
+	Normalized string        `json:"normalized"` // The form the users share
+	Users      []UserSummary `json:"users"`      // Without their order counts
+}
+
+// Account statuses admins can filter users by
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDeleted   = "deleted"
+)
+
+// UserFilter defines the query parameters of the admin user listing
+type UserFilter struct {
+	Query   string `query:"q" json:"q" validate:"max=100"`                                            // Part of the username or email, any case
+	Role    string `query:"role" json:"role" validate:"omitempty,oneof=user admin"`                   // Only users with this role
+	Status  string `query:"status" json:"status" validate:"omitempty,oneof=active suspended deleted"` // Only active, suspended or deleted users
+	Page    int    `query:"page" json:"page" validate:"omitempty,min=1"`                              // 1 when left out
+	PerPage int    `query:"per_page" json:"per_page" validate:"omitempty,min=1,max=100"`              // 20 when left out
+}
+
+// DeleteUsersRequest defines the structure of the request to delete several users at once
+type DeleteUsersRequest struct {
+	Users []uint `json:"user" validate:"required,min=1,max=100,unique,dive,gt=0"`
+}
+
+// UpdateProfileRequest defines the structure of the request to edit the current user.
+// Fields left out are not changed, empty display info clears it.
+type UpdateProfileRequest struct {
+	Username         *string `json:"username" validate:"omitnil,min=1,max=50,unique_username"`
+	Email            *string `json:"email" validate:"omitnil,email,max=255,unique_email"`
+	Shelter          *string `json:"shelter" validate:"omitnil,max=100"`
+	Sector           *string `json:"sector" validate:"omitnil,max=100"`
+	DeliveryLocation *string `json:"delivery_location" validate:"omitnil,max=255"`
+}
+
+// DeleteAccountRequest defines the structure of the request to delete the current user
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// ProfileResponse defines the structure of the response for the profile of the current user
+type ProfileResponse struct {
+	Code    int         `json:"code"`
+	Message UserProfile `json:"message"`
+	// New JWT naming the new email, set when it changed. The previous one still works, JWTs identify users by ID.
+	Token string `json:"token,omitempty"`
 }
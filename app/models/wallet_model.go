@@ -0,0 +1,47 @@
+// app/models/wallet_model.go
+
+package models
+
+import "gorm.io/gorm"
+
+const (
+	TransactionGrant    = "grant"
+	TransactionPurchase = "purchase"
+	TransactionRefund   = "refund"
+)
+
+// Wallet model holds the credit balance of a user
+type Wallet struct {
+	gorm.Model         // Embeds fields `ID`, `CreatedAt`, `UpdatedAt`, `DeletedAt`
+	UserID     uint    `json:"user_id" gorm:"uniqueIndex;not null"` // Owner of the wallet
+	Balance    float64 `json:"balance" gorm:"not null;default:0"`   // Credits available to spend
+}
+
+// WalletTransaction model is an entry of the credit ledger of a wallet
+type WalletTransaction struct {
+	gorm.Model
+	UserID      uint    `json:"user_id" gorm:"not null;index"` // Owner of the wallet
+	Amount      float64 `json:"amount" gorm:"not null"`        // Positive for credits, negative for debits
+	Type        string  `json:"type" gorm:"not null"`          // Type of the entry (e.g., "grant", "purchase", "refund")
+	OrderID     *uint   `json:"order_id" gorm:"index"`         // Order that caused the entry, if any
+	Description string  `json:"description"`                   // Human readable reason
+}
+
+// GrantCreditsRequest defines the structure of the request to grant credits to a user
+type GrantCreditsRequest struct {
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Description string  `json:"description" validate:"required,max=255"`
+}
+
+// WalletResponse defines the structure of the response for the wallet balance endpoint
+type WalletResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Balance float64 `json:"balance"`
+}
+
+// WalletTransactionsResponse defines the structure of the response for the wallet history endpoint
+type WalletTransactionsResponse struct {
+	Code    int                 `json:"code"`
+	Message []WalletTransaction `json:"message"`
+}
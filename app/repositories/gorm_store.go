@@ -0,0 +1,649 @@
+// app/repositories/gorm_store.go
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormStore implements Store on top of a GORM connection or transaction
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a store backed by db
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Users() UserRepository               { return gormUserRepository{s.db} }
+func (s *GormStore) Offers() OfferRepository             { return gormOfferRepository{s.db} }
+func (s *GormStore) Orders() OrderRepository             { return gormOrderRepository{s.db} }
+func (s *GormStore) Reservations() ReservationRepository { return gormReservationRepository{s.db} }
+func (s *GormStore) Wallets() WalletRepository           { return gormWalletRepository{s.db} }
+func (s *GormStore) Rationing() RationingRepository      { return gormRationingRepository{s.db} }
+func (s *GormStore) Communities() CommunityRepository    { return gormCommunityRepository{s.db} }
+func (s *GormStore) Deliveries() DeliveryRepository      { return gormDeliveryRepository{s.db} }
+func (s *GormStore) Tokens() TokenRepository             { return gormTokenRepository{s.db} }
+func (s *GormStore) APIKeys() APIKeyRepository           { return gormAPIKeyRepository{s.db} }
+
+func (s *GormStore) WithContext(ctx context.Context) Store {
+	return &GormStore{db: s.db.WithContext(ctx)}
+}
+
+func (s *GormStore) Transaction(fn func(Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
+
+// translate replaces the errors of GORM with the ones of this package, so services do not depend on GORM.
+// Unique violations are only recognized when the connection was opened with TranslateError.
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r gormUserRepository) FindByID(id uint) (models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r gormUserRepository) FindForUpdate(id uint) (models.User, error) {
+	var user models.User
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r gormUserRepository) FindAnyByID(id uint) (models.User, error) {
+	var user models.User
+	err := r.db.Unscoped().First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r gormUserRepository) FindByEmail(email string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	return user, translate(err)
+}
+
+func (r gormUserRepository) FindByUsername(username string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	return user, translate(err)
+}
+
+func (r gormUserRepository) Search(filter models.UserFilter) ([]models.UserSummary, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Status == models.UserStatusDeleted {
+		query = r.db.Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case models.UserStatusActive:
+		query = query.Where("suspended_at IS NULL")
+	case models.UserStatusSuspended:
+		query = query.Where("suspended_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	summaries := []models.UserSummary{}
+	err := query.
+		Select("users.id, users.username, users.email, users.role, users.created_at, users.suspended_at, users.deleted_at, " +
+			"(SELECT COUNT(*) FROM orders WHERE orders.user_id = users.id AND orders.deleted_at IS NULL) AS order_count").
+		Order("users.id").
+		Limit(filter.PerPage).
+		Offset((filter.Page - 1) * filter.PerPage).
+		Scan(&summaries).Error
+	return summaries, total, err
+}
+
+// escapeLike makes LIKE match the wildcards % and _ literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r gormUserRepository) Unnormalized() ([]models.User, error) {
+	var users []models.User
+	// normalize_identity is created by migration 0011
+	err := r.db.Where("username <> normalize_identity(username) OR email <> normalize_identity(email)").
+		Order("id").
+		Find(&users).Error
+	return users, err
+}
+
+func (r gormUserRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r gormUserRepository) Delete(user *models.User) error {
+	return r.db.Delete(user).Error
+}
+
+func (r gormUserRepository) Restore(userID uint) error {
+	return r.db.Unscoped().Model(&models.User{}).Where("id = ?", userID).Update("deleted_at", nil).Error
+}
+
+func (r gormUserRepository) UpdateProfile(user *models.User) error {
+	return r.updateProfile(r.db, user)
+}
+
+func (r gormUserRepository) updateProfile(db *gorm.DB, user *models.User) error {
+	return db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"username":          user.Username,
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+		"shelter":           user.Shelter,
+		"sector":            user.Sector,
+		"delivery_location": user.DeliveryLocation,
+	}).Error
+}
+
+func (r gormUserRepository) Anonymize(user *models.User) error {
+	// Deleted users are anonymized too, when their data is purged
+	unscoped := r.db.Unscoped()
+	*user = user.Anonymized()
+	if err := r.updateProfile(unscoped, user); err != nil {
+		return err
+	}
+	if err := unscoped.Model(&models.User{}).Where("id = ?", user.ID).Update("password", user.Password).Error; err != nil {
+		return err
+	}
+	// Tokens hold the email they were sent to
+	if err := unscoped.Where("user_id = ?", user.ID).Delete(&models.UserToken{}).Error; err != nil {
+		return err
+	}
+	if err := unscoped.Where("user_id = ?", user.ID).Delete(&models.APIKey{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Exec("DELETE FROM community_representatives WHERE user_id = ?", user.ID).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(user).Error // Keeps the first deletion date of users already deleted
+}
+
+func (r gormUserRepository) SetSuspended(userID uint, at *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("suspended_at", at).Error
+}
+
+func (r gormUserRepository) UpdatePassword(userID uint, hash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password", hash).Error
+}
+
+func (r gormUserRepository) IncrementTokenVersion(userID uint) error {
+	return r.db.Unscoped().Model(&models.User{}).Where("id = ?", userID).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+func (r gormUserRepository) MarkEmailVerified(userID uint, email string, at time.Time) error {
+	result := r.db.Model(&models.User{}).Where("id = ? AND email = ?", userID, email).Update("email_verified_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type gormOfferRepository struct {
+	db *gorm.DB
+}
+
+func (r gormOfferRepository) List() ([]models.Offer, error) {
+	offers := []models.Offer{}
+	err := r.db.Find(&offers).Error
+	return offers, err
+}
+
+func (r gormOfferRepository) FindByID(id uint) (models.Offer, error) {
+	var offer models.Offer
+	err := r.db.First(&offer, id).Error
+	return offer, translate(err)
+}
+
+func (r gormOfferRepository) FindForUpdate(id uint) (models.Offer, error) {
+	var offer models.Offer
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&offer, id).Error
+	return offer, translate(err)
+}
+
+func (r gormOfferRepository) AdjustStock(id uint, delta int) error {
+	return r.db.Model(&models.Offer{}).Where("id = ?", id).
+		Update("quantity", gorm.Expr("quantity + ?", delta)).Error
+}
+
+type gormOrderRepository struct {
+	db *gorm.DB
+}
+
+func (r gormOrderRepository) ListWithItems() ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Preload("OrderItems").Find(&orders).Error
+	return orders, err
+}
+
+func (r gormOrderRepository) ListByUser(userID uint) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Preload("OrderItems").Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&orders).Error
+	return orders, err
+}
+
+func (r gormOrderRepository) FindByID(id uint) (models.Order, error) {
+	var order models.Order
+	err := r.db.First(&order, id).Error
+	return order, translate(err)
+}
+
+func (r gormOrderRepository) FindForUpdate(id uint) (models.Order, error) {
+	var order models.Order
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	return order, translate(err)
+}
+
+func (r gormOrderRepository) Items(orderID uint) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := r.db.Where("order_id = ?", orderID).Find(&items).Error
+	return items, err
+}
+
+func (r gormOrderRepository) Create(order *models.Order) error {
+	return r.db.Create(order).Error
+}
+
+func (r gormOrderRepository) UpdateStatus(order *models.Order) error {
+	return r.db.Model(order).Updates(map[string]interface{}{
+		"status":         order.Status,
+		"payment_status": order.PaymentStatus,
+	}).Error
+}
+
+type gormReservationRepository struct {
+	db *gorm.DB
+}
+
+func (r gormReservationRepository) Create(reservation *models.Reservation) error {
+	return r.db.Create(reservation).Error
+}
+
+func (r gormReservationRepository) FindForUpdate(id uint) (models.Reservation, error) {
+	var reservation models.Reservation
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+		return reservation, translate(err)
+	}
+	err := r.db.Where("reservation_id = ?", reservation.ID).Find(&reservation.Items).Error
+	return reservation, err
+}
+
+func (r gormReservationRepository) UpdateStatus(reservation *models.Reservation) error {
+	return r.db.Model(reservation).Updates(map[string]interface{}{
+		"status":   reservation.Status,
+		"order_id": reservation.OrderID,
+	}).Error
+}
+
+func (r gormReservationRepository) ReservedQuantities(now time.Time) (map[uint]int, error) {
+	var rows []struct {
+		OfferID  uint
+		Reserved int
+	}
+	err := r.db.Table("reservation_items").
+		Select("reservation_items.offer_id AS offer_id, SUM(reservation_items.quantity) AS reserved").
+		Joins("JOIN reservations ON reservations.id = reservation_items.reservation_id").
+		Where("reservations.status = ? AND reservations.expires_at > ?", models.ReservationActive, now).
+		Where("reservations.deleted_at IS NULL AND reservation_items.deleted_at IS NULL").
+		Group("reservation_items.offer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		reserved[row.OfferID] = row.Reserved
+	}
+	return reserved, nil
+}
+
+func (r gormReservationRepository) Expire(now time.Time) (int64, error) {
+	result := r.db.Model(&models.Reservation{}).
+		Where("status = ? AND expires_at <= ?", models.ReservationActive, now).
+		Update("status", models.ReservationExpired)
+	return result.RowsAffected, result.Error
+}
+
+type gormWalletRepository struct {
+	db *gorm.DB
+}
+
+func (r gormWalletRepository) Balance(userID uint) (float64, error) {
+	var wallet models.Wallet
+	err := r.db.Where("user_id = ?", userID).First(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return wallet.Balance, err
+}
+
+func (r gormWalletRepository) Transactions(userID uint) ([]models.WalletTransaction, error) {
+	transactions := []models.WalletTransaction{}
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&transactions).Error
+	return transactions, err
+}
+
+// ensure creates the wallet of userID if it does not exist yet
+func (r gormWalletRepository) ensure(userID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Wallet{UserID: userID}).Error
+}
+
+func (r gormWalletRepository) Debit(userID uint, amount float64, orderID *uint, description string) error {
+	if err := r.ensure(userID); err != nil {
+		return err
+	}
+	// The balance check and the update happen in a single statement so concurrent checkouts cannot overspend
+	result := r.db.Model(&models.Wallet{}).
+		Where("user_id = ? AND balance >= ?", userID, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientCredits
+	}
+	return r.db.Create(&models.WalletTransaction{
+		UserID:      userID,
+		Amount:      -amount,
+		Type:        models.TransactionPurchase,
+		OrderID:     orderID,
+		Description: description,
+	}).Error
+}
+
+func (r gormWalletRepository) Credit(userID uint, amount float64, txType string, orderID *uint, description string) error {
+	if err := r.ensure(userID); err != nil {
+		return err
+	}
+	if err := r.db.Model(&models.Wallet{}).Where("user_id = ?", userID).
+		Update("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
+		return err
+	}
+	return r.db.Create(&models.WalletTransaction{
+		UserID:      userID,
+		Amount:      amount,
+		Type:        txType,
+		OrderID:     orderID,
+		Description: description,
+	}).Error
+}
+
+type gormRationingRepository struct {
+	db *gorm.DB
+}
+
+func (r gormRationingRepository) List() ([]models.RationingRule, error) {
+	rules := []models.RationingRule{}
+	err := r.db.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r gormRationingRepository) FindByID(id uint) (models.RationingRule, error) {
+	var rule models.RationingRule
+	err := r.db.First(&rule, id).Error
+	return rule, translate(err)
+}
+
+func (r gormRationingRepository) Create(rule *models.RationingRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r gormRationingRepository) Update(rule *models.RationingRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r gormRationingRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.RationingRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormRationingRepository) Bought(userID uint, rule models.RationingRule, since time.Time) (int, error) {
+	query := r.db.Table("order_items").
+		Select("COALESCE(SUM(order_items.quantity), 0)").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN offers ON offers.id = order_items.offer_id").
+		Where("orders.user_id = ? AND orders.created_at >= ?", userID, since).
+		Where("orders.status <> ?", models.OrderCancelled).
+		Where("orders.community_id IS NULL AND orders.deleted_at IS NULL AND order_items.deleted_at IS NULL")
+	if rule.OfferID != nil {
+		query = query.Where("order_items.offer_id = ?", *rule.OfferID)
+	} else {
+		query = query.Where("offers.category = ?", rule.Category)
+	}
+
+	var bought int
+	err := query.Scan(&bought).Error
+	return bought, err
+}
+
+type gormCommunityRepository struct {
+	db *gorm.DB
+}
+
+func (r gormCommunityRepository) List() ([]models.Community, error) {
+	communities := []models.Community{}
+	err := r.db.Preload("Representatives").Order("name").Find(&communities).Error
+	return communities, err
+}
+
+func (r gormCommunityRepository) FindByID(id uint) (models.Community, error) {
+	var community models.Community
+	err := r.db.Preload("Representatives").First(&community, id).Error
+	return community, translate(err)
+}
+
+func (r gormCommunityRepository) Create(community *models.Community) error {
+	return translate(r.db.Create(community).Error)
+}
+
+func (r gormCommunityRepository) IsRepresentative(communityID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("community_representatives").
+		Where("community_id = ? AND user_id = ?", communityID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r gormCommunityRepository) AddRepresentative(communityID, userID uint) error {
+	return r.db.Exec("INSERT INTO community_representatives (community_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		communityID, userID).Error
+}
+
+func (r gormCommunityRepository) RemoveRepresentative(communityID, userID uint) error {
+	return r.db.Exec("DELETE FROM community_representatives WHERE community_id = ? AND user_id = ?", communityID, userID).Error
+}
+
+func (r gormCommunityRepository) CreateSettlement(settlement *models.CommunitySettlement) error {
+	return r.db.Create(settlement).Error
+}
+
+func (r gormCommunityRepository) TradeBalances() ([]models.CommunityTradeBalance, error) {
+	balances := []models.CommunityTradeBalance{}
+	err := r.db.Table("communities").
+		Select(`communities.id AS community_id, communities.name AS name,
+			COALESCE((SELECT SUM(total_amount) FROM orders
+				WHERE orders.community_id = communities.id AND orders.status <> ? AND orders.deleted_at IS NULL), 0) AS ordered_amount,
+			COALESCE((SELECT SUM(total_amount) FROM orders
+				WHERE orders.community_id = communities.id AND orders.status = ? AND orders.deleted_at IS NULL), 0) AS delivered_amount,
+			COALESCE((SELECT SUM(amount) FROM community_settlements
+				WHERE community_settlements.community_id = communities.id AND community_settlements.deleted_at IS NULL), 0) AS settled_amount`,
+			models.OrderCancelled, models.OrderDelivered).
+		Where("communities.deleted_at IS NULL").
+		Order("communities.name").
+		Scan(&balances).Error
+	return balances, err
+}
+
+type gormDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func (r gormDeliveryRepository) UpcomingSlots(now time.Time) ([]models.DeliverySlot, error) {
+	slots := []models.DeliverySlot{}
+	err := r.db.Where("ends_at > ?", now).Order("starts_at").Find(&slots).Error
+	return slots, err
+}
+
+func (r gormDeliveryRepository) CreateSlot(slot *models.DeliverySlot) error {
+	return r.db.Create(slot).Error
+}
+
+func (r gormDeliveryRepository) FindSlotForUpdate(id uint) (models.DeliverySlot, error) {
+	var slot models.DeliverySlot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, id).Error
+	return slot, translate(err)
+}
+
+func (r gormDeliveryRepository) CountBooked(slotID, orderID uint) (int64, error) {
+	var booked int64
+	err := r.db.Model(&models.Delivery{}).Where("slot_id = ? AND order_id <> ?", slotID, orderID).Count(&booked).Error
+	return booked, err
+}
+
+func (r gormDeliveryRepository) FindByOrder(orderID uint) (models.Delivery, error) {
+	var delivery models.Delivery
+	err := r.db.Preload("Slot").Where("order_id = ?", orderID).First(&delivery).Error
+	return delivery, translate(err)
+}
+
+func (r gormDeliveryRepository) Save(delivery *models.Delivery) error {
+	// The slot is saved on its own, through CreateSlot
+	return r.db.Omit("Slot").Save(delivery).Error
+}
+
+func (r gormDeliveryRepository) ForCourier(courierID uint, from, to time.Time) ([]models.Delivery, error) {
+	deliveries := []models.Delivery{}
+	err := r.db.Preload("Slot").
+		Joins("JOIN delivery_slots ON delivery_slots.id = deliveries.slot_id").
+		Where("deliveries.courier_id = ? AND delivery_slots.starts_at >= ? AND delivery_slots.starts_at < ?", courierID, from, to).
+		Order("delivery_slots.starts_at").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r gormDeliveryRepository) MarkDelivered(orderID uint, at time.Time) error {
+	return r.db.Model(&models.Delivery{}).Where("order_id = ?", orderID).Update("delivered_at", at).Error
+}
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r gormTokenRepository) Create(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r gormTokenRepository) Consume(purpose, hash string, now time.Time) (models.UserToken, error) {
+	// Checking and marking the token in one statement keeps concurrent requests from redeeming it twice
+	var token models.UserToken
+	result := r.db.Model(&token).Clauses(clause.Returning{}).
+		Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, hash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return models.UserToken{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.UserToken{}, ErrNotFound
+	}
+	return token, nil
+}
+
+func (r gormTokenRepository) Revoke(userID uint, purpose string, now time.Time) error {
+	return r.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error
+}
+
+type gormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r gormAPIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r gormAPIKeyRepository) FindByID(id uint) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, id).Error
+	return key, translate(err)
+}
+
+func (r gormAPIKeyRepository) FindByPrefix(prefix string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("prefix = ?", prefix).First(&key).Error
+	return key, translate(err)
+}
+
+func (r gormAPIKeyRepository) List(userID uint) ([]models.APIKey, error) {
+	query := r.db.Order("id DESC")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	keys := []models.APIKey{}
+	err := query.Find(&keys).Error
+	return keys, err
+}
+
+func (r gormAPIKeyRepository) CountUsable(userID uint, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r gormAPIKeyRepository) Revoke(id uint, now time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", now).Error
+}
+
+func (r gormAPIKeyRepository) RevokeAll(userID uint, now time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", now).Error
+}
+
+func (r gormAPIKeyRepository) Touch(id uint, now time.Time, ip string) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	}).Error
+}
@@ -0,0 +1,1088 @@
+// app/repositories/memory_store.go
+
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/identity"
+	"gorm.io/gorm"
+)
+
+// MemoryStore is an in-memory Store used to test services without a database.
+// Transactions restore a snapshot of the data when they fail.
+type MemoryStore struct {
+	mu sync.Mutex
+
+	users           map[uint]models.User
+	offers          map[uint]models.Offer
+	orders          map[uint]models.Order
+	reservations    map[uint]models.Reservation
+	tokens          map[uint]models.UserToken
+	apiKeys         map[uint]models.APIKey
+	balances        map[uint]float64
+	ledger          []models.WalletTransaction
+	rules           map[uint]models.RationingRule
+	communities     map[uint]models.Community
+	representatives map[uint][]uint
+	settlements     []models.CommunitySettlement
+	slots           map[uint]models.DeliverySlot
+	deliveries      map[uint]models.Delivery // By order ID
+	reserved        map[uint]int             // Seeded holds, on top of the reservations
+	nextID          uint
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:           make(map[uint]models.User),
+		offers:          make(map[uint]models.Offer),
+		orders:          make(map[uint]models.Order),
+		reservations:    make(map[uint]models.Reservation),
+		tokens:          make(map[uint]models.UserToken),
+		apiKeys:         make(map[uint]models.APIKey),
+		balances:        make(map[uint]float64),
+		rules:           make(map[uint]models.RationingRule),
+		communities:     make(map[uint]models.Community),
+		representatives: make(map[uint][]uint),
+		slots:           make(map[uint]models.DeliverySlot),
+		deliveries:      make(map[uint]models.Delivery),
+		reserved:        make(map[uint]int),
+	}
+}
+
+// AddOffer seeds an offer
+func (s *MemoryStore) AddOffer(offer models.Offer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offers[offer.ID] = offer
+}
+
+// AddRationingRule seeds a rationing rule
+func (s *MemoryStore) AddRationingRule(rule models.RationingRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rule.ID == 0 {
+		rule.ID = s.newID()
+	}
+	s.rules[rule.ID] = rule
+}
+
+// AddRepresentative makes userID a representative of communityID
+func (s *MemoryStore) AddRepresentative(communityID, userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.representatives[communityID] = append(s.representatives[communityID], userID)
+}
+
+// AddDelivery schedules the delivery of orderID
+func (s *MemoryStore) AddDelivery(orderID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[orderID] = models.Delivery{OrderID: orderID}
+}
+
+// SetReserved sets the quantity of offerID held by reservations
+func (s *MemoryStore) SetReserved(offerID uint, quantity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reserved[offerID] = quantity
+}
+
+// Balance returns the wallet balance of userID
+func (s *MemoryStore) Balance(userID uint) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balances[userID]
+}
+
+func (s *MemoryStore) Users() UserRepository               { return memoryUserRepository{s} }
+func (s *MemoryStore) Offers() OfferRepository             { return memoryOfferRepository{s} }
+func (s *MemoryStore) Orders() OrderRepository             { return memoryOrderRepository{s} }
+func (s *MemoryStore) Reservations() ReservationRepository { return memoryReservationRepository{s} }
+func (s *MemoryStore) Wallets() WalletRepository           { return memoryWalletRepository{s} }
+func (s *MemoryStore) Rationing() RationingRepository      { return memoryRationingRepository{s} }
+func (s *MemoryStore) Communities() CommunityRepository    { return memoryCommunityRepository{s} }
+func (s *MemoryStore) Deliveries() DeliveryRepository      { return memoryDeliveryRepository{s} }
+func (s *MemoryStore) Tokens() TokenRepository             { return memoryTokenRepository{s} }
+func (s *MemoryStore) APIKeys() APIKeyRepository           { return memoryAPIKeyRepository{s} }
+
+// WithContext returns the store itself, it has nothing to trace or cancel
+func (s *MemoryStore) WithContext(ctx context.Context) Store { return s }
+
+func (s *MemoryStore) Transaction(fn func(Store) error) error {
+	snapshot := s.snapshot()
+	if err := fn(s); err != nil {
+		s.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStore) snapshot() *MemoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := NewMemoryStore()
+	for k, v := range s.users {
+		copied.users[k] = v
+	}
+	for k, v := range s.offers {
+		copied.offers[k] = v
+	}
+	for k, v := range s.orders {
+		copied.orders[k] = v
+	}
+	for k, v := range s.reservations {
+		copied.reservations[k] = v
+	}
+	for k, v := range s.tokens {
+		copied.tokens[k] = v
+	}
+	for k, v := range s.apiKeys {
+		copied.apiKeys[k] = v
+	}
+	for k, v := range s.balances {
+		copied.balances[k] = v
+	}
+	copied.ledger = append(copied.ledger, s.ledger...)
+	for k, v := range s.rules {
+		copied.rules[k] = v
+	}
+	for k, v := range s.communities {
+		copied.communities[k] = v
+	}
+	for k, v := range s.representatives {
+		copied.representatives[k] = append([]uint(nil), v...)
+	}
+	copied.settlements = append(copied.settlements, s.settlements...)
+	for k, v := range s.slots {
+		copied.slots[k] = v
+	}
+	for k, v := range s.deliveries {
+		copied.deliveries[k] = v
+	}
+	copied.nextID = s.nextID
+	return copied
+}
+
+func (s *MemoryStore) restore(snapshot *MemoryStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = snapshot.users
+	s.offers = snapshot.offers
+	s.orders = snapshot.orders
+	s.reservations = snapshot.reservations
+	s.tokens = snapshot.tokens
+	s.apiKeys = snapshot.apiKeys
+	s.balances = snapshot.balances
+	s.ledger = snapshot.ledger
+	s.rules = snapshot.rules
+	s.communities = snapshot.communities
+	s.representatives = snapshot.representatives
+	s.settlements = snapshot.settlements
+	s.slots = snapshot.slots
+	s.deliveries = snapshot.deliveries
+	s.nextID = snapshot.nextID
+}
+
+// newID returns the next identifier, shared by every table. The caller must hold the lock.
+func (s *MemoryStore) newID() uint {
+	s.nextID++
+	return s.nextID
+}
+
+type memoryUserRepository struct {
+	s *MemoryStore
+}
+
+// active returns the user with the given ID unless it does not exist or was deleted.
+// The caller must hold the lock.
+func (r memoryUserRepository) active(id uint) (models.User, bool) {
+	user, ok := r.s.users[id]
+	return user, ok && !user.DeletedAt.Valid
+}
+
+func (r memoryUserRepository) FindByID(id uint) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.active(id)
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r memoryUserRepository) FindForUpdate(id uint) (models.User, error) {
+	return r.FindByID(id)
+}
+
+func (r memoryUserRepository) FindAnyByID(id uint) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r memoryUserRepository) find(match func(models.User) bool) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, user := range r.s.users {
+		if !user.DeletedAt.Valid && match(user) {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r memoryUserRepository) FindByEmail(email string) (models.User, error) {
+	return r.find(func(user models.User) bool { return user.Email == email })
+}
+
+func (r memoryUserRepository) FindByUsername(username string) (models.User, error) {
+	return r.find(func(user models.User) bool { return user.Username == username })
+}
+
+func (r memoryUserRepository) Search(filter models.UserFilter) ([]models.UserSummary, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	query := strings.ToLower(filter.Query)
+	var matches []models.User
+	for _, user := range r.s.users {
+		switch {
+		case user.DeletedAt.Valid != (filter.Status == models.UserStatusDeleted):
+		case query != "" && !strings.Contains(strings.ToLower(user.Username), query) && !strings.Contains(strings.ToLower(user.Email), query):
+		case filter.Role != "" && user.Role != filter.Role:
+		case filter.Status == models.UserStatusActive && user.SuspendedAt != nil:
+		case filter.Status == models.UserStatusSuspended && user.SuspendedAt == nil:
+		default:
+			matches = append(matches, user)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+
+	summaries := []models.UserSummary{}
+	for i := (filter.Page - 1) * filter.PerPage; i < len(matches) && len(summaries) < filter.PerPage; i++ {
+		summary := matches[i].Summary()
+		for _, order := range r.s.orders {
+			if order.UserID == summary.ID {
+				summary.OrderCount++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, int64(len(matches)), nil
+}
+
+func (r memoryUserRepository) Unnormalized() ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var users []models.User
+	for _, user := range r.s.users {
+		if !user.DeletedAt.Valid && (!identity.IsNormalized(user.Username) || !identity.IsNormalized(user.Email)) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r memoryUserRepository) Create(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if user.ID == 0 {
+		user.ID = r.s.newID()
+	}
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r memoryUserRepository) Delete(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.active(user.ID)
+	if !ok {
+		return nil
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r memoryUserRepository) Restore(userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	r.s.users[userID] = user
+	return nil
+}
+
+func (r memoryUserRepository) UpdateProfile(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.active(user.ID)
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Username, stored.Email, stored.EmailVerifiedAt = user.Username, user.Email, user.EmailVerifiedAt
+	stored.Shelter, stored.Sector, stored.DeliveryLocation = user.Shelter, user.Sector, user.DeliveryLocation
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r memoryUserRepository) Anonymize(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	anonymized := stored.Anonymized()
+	if !anonymized.DeletedAt.Valid {
+		anonymized.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	r.s.users[user.ID] = anonymized
+	*user = anonymized
+	for id, token := range r.s.tokens {
+		if token.UserID == user.ID {
+			delete(r.s.tokens, id)
+		}
+	}
+	for id, key := range r.s.apiKeys {
+		if key.UserID == user.ID {
+			delete(r.s.apiKeys, id)
+		}
+	}
+	for communityID, userIDs := range r.s.representatives {
+		kept := userIDs[:0]
+		for _, id := range userIDs {
+			if id != user.ID {
+				kept = append(kept, id)
+			}
+		}
+		r.s.representatives[communityID] = kept
+	}
+	return nil
+}
+
+func (r memoryUserRepository) SetSuspended(userID uint, at *time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.active(userID)
+	if !ok {
+		return ErrNotFound
+	}
+	user.SuspendedAt = at
+	r.s.users[userID] = user
+	return nil
+}
+
+func (r memoryUserRepository) UpdatePassword(userID uint, hash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.active(userID)
+	if !ok {
+		return ErrNotFound
+	}
+	user.Password = hash
+	r.s.users[userID] = user
+	return nil
+}
+
+func (r memoryUserRepository) IncrementTokenVersion(userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	user.TokenVersion++
+	r.s.users[userID] = user
+	return nil
+}
+
+func (r memoryUserRepository) MarkEmailVerified(userID uint, email string, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.active(userID)
+	if !ok || user.Email != email {
+		return ErrNotFound
+	}
+	user.EmailVerifiedAt = &at
+	r.s.users[userID] = user
+	return nil
+}
+
+type memoryOfferRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryOfferRepository) List() ([]models.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offers := []models.Offer{}
+	for _, offer := range r.s.offers {
+		offers = append(offers, offer)
+	}
+	return offers, nil
+}
+
+func (r memoryOfferRepository) FindByID(id uint) (models.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offer, ok := r.s.offers[id]
+	if !ok {
+		return models.Offer{}, ErrNotFound
+	}
+	return offer, nil
+}
+
+func (r memoryOfferRepository) FindForUpdate(id uint) (models.Offer, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offer, ok := r.s.offers[id]
+	if !ok {
+		return models.Offer{}, ErrNotFound
+	}
+	return offer, nil
+}
+
+func (r memoryOfferRepository) AdjustStock(id uint, delta int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	offer, ok := r.s.offers[id]
+	if !ok {
+		return ErrNotFound
+	}
+	offer.Quantity += delta
+	r.s.offers[id] = offer
+	return nil
+}
+
+type memoryOrderRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryOrderRepository) ListWithItems() ([]models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var orders []models.Order
+	for _, order := range r.s.orders {
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (r memoryOrderRepository) ListByUser(userID uint) ([]models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var orders []models.Order
+	for _, order := range r.s.orders {
+		if order.UserID == userID {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.After(orders[j].CreatedAt)
+		}
+		return orders[i].ID > orders[j].ID
+	})
+	return orders, nil
+}
+
+func (r memoryOrderRepository) FindByID(id uint) (models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	order, ok := r.s.orders[id]
+	if !ok {
+		return models.Order{}, ErrNotFound
+	}
+	return order, nil
+}
+
+func (r memoryOrderRepository) FindForUpdate(id uint) (models.Order, error) {
+	return r.FindByID(id)
+}
+
+func (r memoryOrderRepository) Items(orderID uint) ([]models.OrderItem, error) {
+	order, err := r.FindByID(orderID)
+	return order.OrderItems, err
+}
+
+func (r memoryOrderRepository) Create(order *models.Order) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	order.ID = r.s.newID()
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+	items := make([]models.OrderItem, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		item.ID = r.s.newID()
+		item.OrderID = order.ID
+		items[i] = item
+	}
+	order.OrderItems = items
+	r.s.orders[order.ID] = *order
+	return nil
+}
+
+func (r memoryOrderRepository) UpdateStatus(order *models.Order) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.orders[order.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Status = order.Status
+	stored.PaymentStatus = order.PaymentStatus
+	r.s.orders[order.ID] = stored
+	return nil
+}
+
+type memoryReservationRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryReservationRepository) Create(reservation *models.Reservation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reservation.ID = r.s.newID()
+	items := make([]models.ReservationItem, len(reservation.Items))
+	for i, item := range reservation.Items {
+		item.ID = r.s.newID()
+		item.ReservationID = reservation.ID
+		items[i] = item
+	}
+	reservation.Items = items
+	r.s.reservations[reservation.ID] = *reservation
+	return nil
+}
+
+func (r memoryReservationRepository) FindForUpdate(id uint) (models.Reservation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reservation, ok := r.s.reservations[id]
+	if !ok {
+		return models.Reservation{}, ErrNotFound
+	}
+	return reservation, nil
+}
+
+func (r memoryReservationRepository) UpdateStatus(reservation *models.Reservation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.reservations[reservation.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.Status, stored.OrderID = reservation.Status, reservation.OrderID
+	r.s.reservations[reservation.ID] = stored
+	return nil
+}
+
+func (r memoryReservationRepository) ReservedQuantities(now time.Time) (map[uint]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reserved := make(map[uint]int, len(r.s.reserved))
+	for k, v := range r.s.reserved {
+		reserved[k] = v
+	}
+	for _, reservation := range r.s.reservations {
+		if reservation.Status != models.ReservationActive || !reservation.ExpiresAt.After(now) {
+			continue
+		}
+		for _, item := range reservation.Items {
+			reserved[item.OfferID] += item.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+func (r memoryReservationRepository) Expire(now time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var expired int64
+	for id, reservation := range r.s.reservations {
+		if reservation.Status == models.ReservationActive && !reservation.ExpiresAt.After(now) {
+			reservation.Status = models.ReservationExpired
+			r.s.reservations[id] = reservation
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+type memoryWalletRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryWalletRepository) Balance(userID uint) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.balances[userID], nil
+}
+
+func (r memoryWalletRepository) Transactions(userID uint) ([]models.WalletTransaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	transactions := []models.WalletTransaction{}
+	for i := len(r.s.ledger) - 1; i >= 0; i-- {
+		if r.s.ledger[i].UserID == userID {
+			transactions = append(transactions, r.s.ledger[i])
+		}
+	}
+	return transactions, nil
+}
+
+// record appends an entry to the ledger. The caller must hold the lock.
+func (r memoryWalletRepository) record(entry models.WalletTransaction) {
+	entry.ID = r.s.newID()
+	r.s.ledger = append(r.s.ledger, entry)
+}
+
+func (r memoryWalletRepository) Debit(userID uint, amount float64, orderID *uint, description string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.balances[userID] < amount {
+		return ErrInsufficientCredits
+	}
+	r.s.balances[userID] -= amount
+	r.record(models.WalletTransaction{UserID: userID, Amount: -amount, Type: models.TransactionPurchase, OrderID: orderID, Description: description})
+	return nil
+}
+
+func (r memoryWalletRepository) Credit(userID uint, amount float64, txType string, orderID *uint, description string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.balances[userID] += amount
+	r.record(models.WalletTransaction{UserID: userID, Amount: amount, Type: txType, OrderID: orderID, Description: description})
+	return nil
+}
+
+type memoryRationingRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryRationingRepository) List() ([]models.RationingRule, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rules := []models.RationingRule{}
+	for _, rule := range r.s.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+func (r memoryRationingRepository) FindByID(id uint) (models.RationingRule, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rule, ok := r.s.rules[id]
+	if !ok {
+		return models.RationingRule{}, ErrNotFound
+	}
+	return rule, nil
+}
+
+func (r memoryRationingRepository) Create(rule *models.RationingRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rule.ID = r.s.newID()
+	r.s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r memoryRationingRepository) Update(rule *models.RationingRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.rules[rule.ID]; !ok {
+		return ErrNotFound
+	}
+	r.s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (r memoryRationingRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.rules, id)
+	return nil
+}
+
+func (r memoryRationingRepository) Bought(userID uint, rule models.RationingRule, since time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	bought := 0
+	for _, order := range r.s.orders {
+		if order.UserID != userID || order.CommunityID != nil || order.Status == models.OrderCancelled || order.CreatedAt.Before(since) {
+			continue
+		}
+		for _, item := range order.OrderItems {
+			offer := r.s.offers[item.OfferID]
+			if (rule.OfferID != nil && *rule.OfferID == offer.ID) || (rule.OfferID == nil && rule.Category == offer.Category) {
+				bought += item.Quantity
+			}
+		}
+	}
+	return bought, nil
+}
+
+type memoryCommunityRepository struct {
+	s *MemoryStore
+}
+
+// withRepresentatives fills in the representatives of community. The caller must hold the lock.
+func (r memoryCommunityRepository) withRepresentatives(community models.Community) models.Community {
+	community.Representatives = []models.User{}
+	for _, userID := range r.s.representatives[community.ID] {
+		if user, ok := r.s.users[userID]; ok {
+			community.Representatives = append(community.Representatives, user)
+		}
+	}
+	return community
+}
+
+func (r memoryCommunityRepository) List() ([]models.Community, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	communities := []models.Community{}
+	for _, community := range r.s.communities {
+		communities = append(communities, r.withRepresentatives(community))
+	}
+	sort.Slice(communities, func(i, j int) bool { return communities[i].Name < communities[j].Name })
+	return communities, nil
+}
+
+func (r memoryCommunityRepository) FindByID(id uint) (models.Community, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	community, ok := r.s.communities[id]
+	if !ok {
+		return models.Community{}, ErrNotFound
+	}
+	return r.withRepresentatives(community), nil
+}
+
+func (r memoryCommunityRepository) Create(community *models.Community) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.communities {
+		if existing.Name == community.Name {
+			return ErrDuplicate
+		}
+	}
+	community.ID = r.s.newID()
+	r.s.communities[community.ID] = *community
+	return nil
+}
+
+func (r memoryCommunityRepository) IsRepresentative(communityID, userID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range r.s.representatives[communityID] {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryCommunityRepository) AddRepresentative(communityID, userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range r.s.representatives[communityID] {
+		if id == userID {
+			return nil
+		}
+	}
+	r.s.representatives[communityID] = append(r.s.representatives[communityID], userID)
+	return nil
+}
+
+func (r memoryCommunityRepository) RemoveRepresentative(communityID, userID uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var kept []uint
+	for _, id := range r.s.representatives[communityID] {
+		if id != userID {
+			kept = append(kept, id)
+		}
+	}
+	r.s.representatives[communityID] = kept
+	return nil
+}
+
+func (r memoryCommunityRepository) CreateSettlement(settlement *models.CommunitySettlement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	settlement.ID = r.s.newID()
+	r.s.settlements = append(r.s.settlements, *settlement)
+	return nil
+}
+
+func (r memoryCommunityRepository) TradeBalances() ([]models.CommunityTradeBalance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	balances := []models.CommunityTradeBalance{}
+	for _, community := range r.s.communities {
+		balance := models.CommunityTradeBalance{CommunityID: community.ID, Name: community.Name}
+		for _, order := range r.s.orders {
+			if order.CommunityID == nil || *order.CommunityID != community.ID || order.Status == models.OrderCancelled {
+				continue
+			}
+			balance.OrderedAmount += order.TotalAmount
+			if order.Status == models.OrderDelivered {
+				balance.DeliveredAmount += order.TotalAmount
+			}
+		}
+		for _, settlement := range r.s.settlements {
+			if settlement.CommunityID == community.ID {
+				balance.SettledAmount += settlement.Amount
+			}
+		}
+		balances = append(balances, balance)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Name < balances[j].Name })
+	return balances, nil
+}
+
+type memoryDeliveryRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryDeliveryRepository) UpcomingSlots(now time.Time) ([]models.DeliverySlot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	slots := []models.DeliverySlot{}
+	for _, slot := range r.s.slots {
+		if slot.EndsAt.After(now) {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots, nil
+}
+
+func (r memoryDeliveryRepository) CreateSlot(slot *models.DeliverySlot) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	slot.ID = r.s.newID()
+	r.s.slots[slot.ID] = *slot
+	return nil
+}
+
+func (r memoryDeliveryRepository) FindSlotForUpdate(id uint) (models.DeliverySlot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	slot, ok := r.s.slots[id]
+	if !ok {
+		return models.DeliverySlot{}, ErrNotFound
+	}
+	return slot, nil
+}
+
+func (r memoryDeliveryRepository) CountBooked(slotID, orderID uint) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var booked int64
+	for _, delivery := range r.s.deliveries {
+		if delivery.SlotID == slotID && delivery.OrderID != orderID {
+			booked++
+		}
+	}
+	return booked, nil
+}
+
+func (r memoryDeliveryRepository) FindByOrder(orderID uint) (models.Delivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delivery, ok := r.s.deliveries[orderID]
+	if !ok {
+		return models.Delivery{}, ErrNotFound
+	}
+	delivery.Slot = r.s.slots[delivery.SlotID]
+	return delivery, nil
+}
+
+func (r memoryDeliveryRepository) Save(delivery *models.Delivery) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if delivery.ID == 0 {
+		delivery.ID = r.s.newID()
+	}
+	r.s.deliveries[delivery.OrderID] = *delivery
+	return nil
+}
+
+func (r memoryDeliveryRepository) ForCourier(courierID uint, from, to time.Time) ([]models.Delivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	deliveries := []models.Delivery{}
+	for _, delivery := range r.s.deliveries {
+		slot := r.s.slots[delivery.SlotID]
+		if delivery.CourierID == courierID && !slot.StartsAt.Before(from) && slot.StartsAt.Before(to) {
+			delivery.Slot = slot
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Slot.StartsAt.Before(deliveries[j].Slot.StartsAt) })
+	return deliveries, nil
+}
+
+func (r memoryDeliveryRepository) MarkDelivered(orderID uint, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if delivery, ok := r.s.deliveries[orderID]; ok {
+		delivery.DeliveredAt = &at
+		r.s.deliveries[orderID] = delivery
+	}
+	return nil
+}
+
+type memoryTokenRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryTokenRepository) Create(token *models.UserToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if token.ID == 0 {
+		token.ID = r.s.newID()
+	}
+	r.s.tokens[token.ID] = *token
+	return nil
+}
+
+func (r memoryTokenRepository) Consume(purpose, hash string, now time.Time) (models.UserToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, token := range r.s.tokens {
+		if token.Purpose == purpose && token.TokenHash == hash && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			r.s.tokens[id] = token
+			return token, nil
+		}
+	}
+	return models.UserToken{}, ErrNotFound
+}
+
+func (r memoryTokenRepository) Revoke(userID uint, purpose string, now time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, token := range r.s.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+			r.s.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+type memoryAPIKeyRepository struct {
+	s *MemoryStore
+}
+
+func (r memoryAPIKeyRepository) Create(key *models.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if key.ID == 0 {
+		key.ID = r.s.newID()
+	}
+	r.s.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r memoryAPIKeyRepository) FindByID(id uint) (models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	key, ok := r.s.apiKeys[id]
+	if !ok {
+		return models.APIKey{}, ErrNotFound
+	}
+	return key, nil
+}
+
+func (r memoryAPIKeyRepository) FindByPrefix(prefix string) (models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, key := range r.s.apiKeys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, ErrNotFound
+}
+
+func (r memoryAPIKeyRepository) List(userID uint) ([]models.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keys := []models.APIKey{}
+	for _, key := range r.s.apiKeys {
+		if userID == 0 || key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+	return keys, nil
+}
+
+func (r memoryAPIKeyRepository) CountUsable(userID uint, now time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for _, key := range r.s.apiKeys {
+		if key.UserID == userID && key.Usable(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryAPIKeyRepository) Revoke(id uint, now time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if key, ok := r.s.apiKeys[id]; ok && key.RevokedAt == nil {
+		key.RevokedAt = &now
+		r.s.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r memoryAPIKeyRepository) RevokeAll(userID uint, now time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, key := range r.s.apiKeys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			key.RevokedAt = &now
+			r.s.apiKeys[id] = key
+		}
+	}
+	return nil
+}
+
+func (r memoryAPIKeyRepository) Touch(id uint, now time.Time, ip string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if key, ok := r.s.apiKeys[id]; ok {
+		key.LastUsedAt, key.LastUsedIP = &now, ip
+		r.s.apiKeys[id] = key
+	}
+	return nil
+}
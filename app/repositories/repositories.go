@@ -0,0 +1,203 @@
+// app/repositories/repositories.go
+
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+)
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate is returned when a record would break a unique index
+var ErrDuplicate = errors.New("duplicate record")
+
+// ErrInsufficientCredits is returned when a wallet cannot cover a debit
+var ErrInsufficientCredits = errors.New("insufficient credits")
+
+// UserRepository stores the users of the market
+type UserRepository interface {
+	FindByID(id uint) (models.User, error)
+	// FindForUpdate finds the user with the given ID and locks its row until the transaction ends
+	FindForUpdate(id uint) (models.User, error)
+	// FindAnyByID finds the user with the given ID even if it was deleted
+	FindAnyByID(id uint) (models.User, error)
+	FindByEmail(email string) (models.User, error)
+	FindByUsername(username string) (models.User, error)
+	// Search returns one page of the users matching filter ordered by ID, with their order counts,
+	// and how many users match in total. Deleted users are only listed by the deleted status.
+	// Page and PerPage must be set.
+	Search(filter models.UserFilter) ([]models.UserSummary, int64, error)
+	// Unnormalized returns the users not deleted whose username or email is not stored normalized,
+	// ordered by ID. Those are the users who collided with others when identities were normalized.
+	Unnormalized() ([]models.User, error)
+	Create(user *models.User) error
+	// Delete soft-deletes user, which Restore undoes
+	Delete(user *models.User) error
+	Restore(userID uint) error
+	// UpdateProfile persists the username, email, email verification and display info of user
+	UpdateProfile(user *models.User) error
+	// Anonymize replaces the personal data of user with placeholders, drops its tokens, API keys and
+	// community representations and deletes it if it was not already. Its orders and wallet history are kept.
+	Anonymize(user *models.User) error
+	// SetSuspended suspends userID from at, or lifts the suspension when at is nil
+	SetSuspended(userID uint, at *time.Time) error
+	// UpdatePassword replaces the password hash of userID
+	UpdatePassword(userID uint, hash string) error
+	// IncrementTokenVersion revokes the JWTs issued to userID so far, deleted or not
+	IncrementTokenVersion(userID uint) error
+	// MarkEmailVerified records that userID owns email, failing with ErrNotFound if it is no longer their email
+	MarkEmailVerified(userID uint, email string, at time.Time) error
+}
+
+// TokenRepository stores the single-use tokens mailed to users
+type TokenRepository interface {
+	Create(token *models.UserToken) error
+	// Consume marks the unused and unexpired token with the given purpose and hash as used and returns it,
+	// failing with ErrNotFound otherwise. A token can only be consumed once, even by concurrent requests.
+	Consume(purpose, hash string, now time.Time) (models.UserToken, error)
+	// Revoke marks every unused token of userID with the given purpose as used
+	Revoke(userID uint, purpose string, now time.Time) error
+}
+
+// APIKeyRepository stores the API keys machine clients authenticate with
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id uint) (models.APIKey, error)
+	// FindByPrefix finds the key starting with prefix, revoked and expired keys included
+	FindByPrefix(prefix string) (models.APIKey, error)
+	// List returns the keys of userID, or of every user when userID is 0, newest first
+	List(userID uint) ([]models.APIKey, error)
+	// CountUsable counts the keys of userID that are neither revoked nor expired at now
+	CountUsable(userID uint, now time.Time) (int64, error)
+	// Revoke marks the key with the given ID as revoked from now on
+	Revoke(id uint, now time.Time) error
+	// RevokeAll marks every key of userID not revoked yet as revoked from now on
+	RevokeAll(userID uint, now time.Time) error
+	// Touch records that the key with the given ID was used at now from ip
+	Touch(id uint, now time.Time, ip string) error
+}
+
+// OfferRepository stores the offers and their stock
+type OfferRepository interface {
+	List() ([]models.Offer, error)
+	FindByID(id uint) (models.Offer, error)
+	// FindForUpdate loads an offer and locks it until the end of the transaction
+	FindForUpdate(id uint) (models.Offer, error)
+	// AdjustStock adds delta, which may be negative, to the quantity of an offer
+	AdjustStock(id uint, delta int) error
+}
+
+// ReservationRepository stores the stock held by buyers before they pay
+type ReservationRepository interface {
+	Create(reservation *models.Reservation) error
+	// FindForUpdate loads a reservation with its items and locks it until the end of the transaction
+	FindForUpdate(id uint) (models.Reservation, error)
+	// UpdateStatus persists the status and order of reservation
+	UpdateStatus(reservation *models.Reservation) error
+	// ReservedQuantities returns the quantity held by active, unexpired reservations grouped by offer ID
+	ReservedQuantities(now time.Time) (map[uint]int, error)
+	// Expire marks the active reservations whose TTL has passed at now as expired and counts them
+	Expire(now time.Time) (int64, error)
+}
+
+// OrderRepository stores the orders and their items
+type OrderRepository interface {
+	ListWithItems() ([]models.Order, error)
+	// ListByUser returns the orders placed by userID with their items, newest first
+	ListByUser(userID uint) ([]models.Order, error)
+	FindByID(id uint) (models.Order, error)
+	// FindForUpdate loads an order and locks it until the end of the transaction
+	FindForUpdate(id uint) (models.Order, error)
+	Items(orderID uint) ([]models.OrderItem, error)
+	Create(order *models.Order) error
+	// UpdateStatus persists the status and payment status of order
+	UpdateStatus(order *models.Order) error
+}
+
+// WalletRepository stores the credit balances of users and their ledger
+type WalletRepository interface {
+	// Balance returns the credits of userID, 0 when they have no wallet yet
+	Balance(userID uint) (float64, error)
+	// Transactions returns the ledger entries of userID, newest first
+	Transactions(userID uint) ([]models.WalletTransaction, error)
+	// Debit takes amount from the wallet of userID, failing with ErrInsufficientCredits if it cannot cover it
+	Debit(userID uint, amount float64, orderID *uint, description string) error
+	// Credit adds amount to the wallet of userID
+	Credit(userID uint, amount float64, txType string, orderID *uint, description string) error
+}
+
+// RationingRepository stores the rationing rules and reads the purchases they limit
+type RationingRepository interface {
+	// List returns every rule ordered by ID
+	List() ([]models.RationingRule, error)
+	FindByID(id uint) (models.RationingRule, error)
+	Create(rule *models.RationingRule) error
+	// Update persists the target, limit and window of rule
+	Update(rule *models.RationingRule) error
+	// Delete removes the rule with the given ID, failing with ErrNotFound if there is none
+	Delete(id uint) error
+	// Bought sums the units userID ordered for themselves under rule since the given instant.
+	// Cancelled orders were returned to stock, so they do not count.
+	Bought(userID uint, rule models.RationingRule, since time.Time) (int, error)
+}
+
+// CommunityRepository stores the trade partner communities, their representatives and settlements
+type CommunityRepository interface {
+	// List returns every community with its representatives, ordered by name
+	List() ([]models.Community, error)
+	// FindByID returns a community with its representatives
+	FindByID(id uint) (models.Community, error)
+	// Create fails with ErrDuplicate if the name is taken
+	Create(community *models.Community) error
+	// IsRepresentative reports whether userID may place orders on behalf of communityID
+	IsRepresentative(communityID, userID uint) (bool, error)
+	AddRepresentative(communityID, userID uint) error
+	RemoveRepresentative(communityID, userID uint) error
+	CreateSettlement(settlement *models.CommunitySettlement) error
+	// TradeBalances returns what every community ordered, received and paid back, ordered by name.
+	// Balance is left for the caller to compute.
+	TradeBalances() ([]models.CommunityTradeBalance, error)
+}
+
+// DeliveryRepository stores the delivery slots and the deliveries scheduled in them
+type DeliveryRepository interface {
+	// UpcomingSlots returns the slots that have not ended at now, earliest first
+	UpcomingSlots(now time.Time) ([]models.DeliverySlot, error)
+	CreateSlot(slot *models.DeliverySlot) error
+	// FindSlotForUpdate loads a slot and locks it until the end of the transaction,
+	// so two deliveries cannot both take its last place
+	FindSlotForUpdate(id uint) (models.DeliverySlot, error)
+	// CountBooked counts the deliveries scheduled in slotID other than the one of orderID
+	CountBooked(slotID, orderID uint) (int64, error)
+	// FindByOrder returns the delivery of orderID with its slot
+	FindByOrder(orderID uint) (models.Delivery, error)
+	// Save creates delivery or updates its slot, courier, destination and notes
+	Save(delivery *models.Delivery) error
+	// ForCourier returns the deliveries of courierID in slots starting from from until to, earliest first
+	ForCourier(courierID uint, from, to time.Time) ([]models.Delivery, error)
+	// MarkDelivered records that the delivery of orderID was handed over at
+	MarkDelivered(orderID uint, at time.Time) error
+}
+
+// Store gives access to every repository and runs them in a single unit of work
+type Store interface {
+	Users() UserRepository
+	Offers() OfferRepository
+	Orders() OrderRepository
+	Reservations() ReservationRepository
+	Wallets() WalletRepository
+	Rationing() RationingRepository
+	Communities() CommunityRepository
+	Deliveries() DeliveryRepository
+	Tokens() TokenRepository
+	APIKeys() APIKeyRepository
+	// Transaction runs fn with repositories bound to one transaction, rolled back if fn fails
+	Transaction(fn func(Store) error) error
+	// WithContext returns a store whose queries run with ctx, so they are traced and cancelled with the request
+	WithContext(ctx context.Context) Store
+}
@@ -0,0 +1,181 @@
+// app/services/account_service.go
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/mailer"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenBytes is the amount of randomness in every mailed token
+const tokenBytes = 32
+
+// AccountService manages the credentials of users: password changes and resets and email verification
+type AccountService struct {
+	Store     repositories.Store
+	Validator *validation.Validator
+	Mailer    mailer.Mailer
+	Clock     clock.Clock
+	Tokens    config.TokensConfig
+	Mail      config.MailConfig
+}
+
+func NewAccountService(store repositories.Store, validator *validation.Validator, m mailer.Mailer, clk clock.Clock, tokens config.TokensConfig, mail config.MailConfig) *AccountService {
+	return &AccountService{Store: store, Validator: validator, Mailer: m, Clock: clk, Tokens: tokens, Mail: mail}
+}
+
+// ChangePassword replaces the password of user after checking the current one, and revokes
+// the password reset tokens they may have asked for
+func (s *AccountService) ChangePassword(ctx context.Context, user models.User, request models.ChangePasswordRequest) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.CurrentPassword)); err != nil {
+		return ruleError(ReasonForbidden, "current password is incorrect")
+	}
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		return s.setPassword(store, user, request.NewPassword)
+	})
+}
+
+// RequestPasswordReset mails a password reset token to the user registered with email.
+// Unknown emails are ignored, so the endpoint does not reveal who has an account.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	store := s.Store.WithContext(ctx)
+	user, err := findByTypedEmail(store.Users(), email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(store, user, models.TokenPasswordReset, s.Tokens.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of %s. If it was you, open %s within %s to choose a new one.\n"+
+			"If it was not you, ignore this message and your password will stay the same.",
+			user.Username, s.link("/reset-password", token), s.Tokens.PasswordResetTTL),
+	})
+}
+
+// ResetPassword sets a new password for the owner of a password reset token. The token is
+// only spent when the new password is accepted.
+func (s *AccountService) ResetPassword(ctx context.Context, request models.ResetPasswordRequest) error {
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		token, err := store.Tokens().Consume(models.TokenPasswordReset, hashToken(request.Token), s.Clock.Now())
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonInvalidToken, "the reset token is invalid or has expired")
+		}
+		if err != nil {
+			return err
+		}
+		user, err := store.Users().FindByID(token.UserID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonInvalidToken, "the reset token is invalid or has expired")
+		}
+		if err != nil {
+			return err
+		}
+		return s.setPassword(store, user, request.NewPassword)
+	})
+}
+
+// SendVerification mails a token to prove user owns their email, replacing any token sent before
+func (s *AccountService) SendVerification(ctx context.Context, user models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ruleError(ReasonAlreadyVerified, "email is already verified")
+	}
+
+	store := s.Store.WithContext(ctx)
+	if err := store.Tokens().Revoke(user.ID, models.TokenEmailVerification, s.Clock.Now()); err != nil {
+		return err
+	}
+	token, err := s.issueToken(store, user, models.TokenEmailVerification, s.Tokens.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Welcome to New World, %s. Open %s within %s to confirm this is your email.",
+			user.Username, s.link("/verify-email", token), s.Tokens.EmailVerificationTTL),
+	})
+}
+
+// VerifyEmail marks the email a verification token was mailed to as verified. Tokens sent to
+// an address the user has changed since are rejected.
+func (s *AccountService) VerifyEmail(ctx context.Context, request models.VerifyEmailRequest) error {
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		now := s.Clock.Now()
+		token, err := store.Tokens().Consume(models.TokenEmailVerification, hashToken(request.Token), now)
+		if err == nil {
+			err = store.Users().MarkEmailVerified(token.UserID, token.Email, now)
+		}
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonInvalidToken, "the verification token is invalid or has expired")
+		}
+		return err
+	})
+}
+
+// setPassword checks password against the policy, stores its hash and revokes the reset tokens of user
+func (s *AccountService) setPassword(store repositories.Store, user models.User, password string) error {
+	if err := s.Validator.Password("new_password", password, user.Username, user.Email); err != nil {
+		return err
+	}
+	hash, err := utils.BcryptGenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := store.Users().UpdatePassword(user.ID, string(hash)); err != nil {
+		return err
+	}
+	return store.Tokens().Revoke(user.ID, models.TokenPasswordReset, s.Clock.Now())
+}
+
+// issueToken stores the hash of a new random token for user and returns the token to mail
+func (s *AccountService) issueToken(store repositories.Store, user models.User, purpose string, ttl time.Duration) (string, error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := store.Tokens().Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: s.Clock.Now().Add(ttl),
+	})
+	return token, err
+}
+
+// link builds the frontend URL that redeems token
+func (s *AccountService) link(path, token string) string {
+	return s.Mail.LinkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// hashToken returns the hex SHA-256 of a token, which is how tokens are stored and looked up
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
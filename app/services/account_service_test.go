@@ -0,0 +1,197 @@
+// app/services/account_service_test.go
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/mailer"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// outbox is a Mailer that keeps the messages it is asked to send
+type outbox struct {
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// lastToken returns the token in the link of the last message sent
+func (o *outbox) lastToken(t *testing.T) string {
+	if len(o.messages) == 0 {
+		t.Fatalf("No message was sent")
+	}
+	match := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(o.messages[len(o.messages)-1].Body)
+	if match == nil {
+		t.Fatalf("The last message has no token link")
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("Failed to unescape token: %s", err)
+	}
+	return token
+}
+
+var accountNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func setupAccounts(t *testing.T, now time.Time) (*services.AccountService, *repositories.MemoryStore, *outbox, models.User) {
+	store := repositories.NewMemoryStore()
+	hash, err := bcrypt.GenerateFromPassword([]byte("Old-Passw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %s", err)
+	}
+	user := models.User{Username: "mia", Email: "mia@example.com", Password: string(hash), Role: services.RoleUser}
+	if err := store.Users().Create(&user); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	cfg := config.Default()
+	mail := &outbox{}
+	accounts := services.NewAccountService(store, validation.New(store, cfg.Password), mail, clock.Fixed(now), cfg.Tokens, cfg.Mail)
+	return accounts, store, mail, user
+}
+
+func passwordMatches(t *testing.T, store repositories.Store, userID uint, password string) bool {
+	user, err := store.Users().FindByID(userID)
+	if err != nil {
+		t.Fatalf("Failed to find user: %s", err)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+func TestChangePassword(t *testing.T) {
+	accounts, store, _, user := setupAccounts(t, accountNow)
+
+	err := accounts.ChangePassword(context.Background(), user, models.ChangePasswordRequest{
+		CurrentPassword: "wrong",
+		NewPassword:     "Gr33n-Valley!",
+	})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+	}
+
+	err = accounts.ChangePassword(context.Background(), user, models.ChangePasswordRequest{
+		CurrentPassword: "Old-Passw0rd!",
+		NewPassword:     "Mia-2026-rocks",
+	})
+	fieldErrs, ok := validation.AsErrors(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, validation.RulePasswordIdentity, fieldErrs[0].Rule)
+	}
+
+	err = accounts.ChangePassword(context.Background(), user, models.ChangePasswordRequest{
+		CurrentPassword: "Old-Passw0rd!",
+		NewPassword:     "Gr33n-Valley!",
+	})
+	assert.NoError(t, err)
+	assert.True(t, passwordMatches(t, store, user.ID, "Gr33n-Valley!"))
+}
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	accounts, store, mail, user := setupAccounts(t, accountNow)
+
+	if err := accounts.RequestPasswordReset(context.Background(), user.Email); err != nil {
+		t.Fatalf("Failed to request reset: %s", err)
+	}
+	token := mail.lastToken(t)
+	assert.Equal(t, user.Email, mail.messages[0].To)
+
+	// A rejected password does not spend the token
+	err := accounts.ResetPassword(context.Background(), models.ResetPasswordRequest{Token: token, NewPassword: "short"})
+	_, ok := validation.AsErrors(err)
+	assert.True(t, ok)
+
+	err = accounts.ResetPassword(context.Background(), models.ResetPasswordRequest{Token: token, NewPassword: "Gr33n-Valley!"})
+	assert.NoError(t, err)
+	assert.True(t, passwordMatches(t, store, user.ID, "Gr33n-Valley!"))
+
+	err = accounts.ResetPassword(context.Background(), models.ResetPasswordRequest{Token: token, NewPassword: "An0ther-Valley!"})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidToken, ruleErr.Reason)
+	}
+}
+
+func TestResetPasswordTokenExpires(t *testing.T) {
+	accounts, store, mail, user := setupAccounts(t, accountNow)
+	if err := accounts.RequestPasswordReset(context.Background(), user.Email); err != nil {
+		t.Fatalf("Failed to request reset: %s", err)
+	}
+
+	accounts.Clock = clock.Fixed(accountNow.Add(accounts.Tokens.PasswordResetTTL))
+	err := accounts.ResetPassword(context.Background(), models.ResetPasswordRequest{Token: mail.lastToken(t), NewPassword: "Gr33n-Valley!"})
+
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidToken, ruleErr.Reason)
+	}
+	assert.True(t, passwordMatches(t, store, user.ID, "Old-Passw0rd!"))
+}
+
+func TestRequestPasswordResetIgnoresUnknownEmail(t *testing.T) {
+	accounts, _, mail, _ := setupAccounts(t, accountNow)
+
+	assert.NoError(t, accounts.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	assert.Empty(t, mail.messages)
+}
+
+func TestRequestPasswordResetMatchesEmailAsTyped(t *testing.T) {
+	accounts, store, mail, _ := setupAccounts(t, accountNow)
+	// Left as typed because its normalized email belongs to mia
+	legacy := models.User{Username: "Mia2", Email: "Mia@Example.com", Role: services.RoleUser}
+	if err := store.Users().Create(&legacy); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	assert.NoError(t, accounts.RequestPasswordReset(context.Background(), " MIA@example.COM"))
+	assert.NoError(t, accounts.RequestPasswordReset(context.Background(), "Mia@Example.com"))
+	if assert.Len(t, mail.messages, 2) {
+		assert.Equal(t, "mia@example.com", mail.messages[0].To)
+		assert.Equal(t, "Mia@Example.com", mail.messages[1].To)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	accounts, store, mail, user := setupAccounts(t, accountNow)
+
+	if err := accounts.SendVerification(context.Background(), user); err != nil {
+		t.Fatalf("Failed to send verification: %s", err)
+	}
+	first := mail.lastToken(t)
+	if err := accounts.SendVerification(context.Background(), user); err != nil {
+		t.Fatalf("Failed to send verification: %s", err)
+	}
+
+	// Only the last token mailed is valid
+	err := accounts.VerifyEmail(context.Background(), models.VerifyEmailRequest{Token: first})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidToken, ruleErr.Reason)
+	}
+
+	assert.NoError(t, accounts.VerifyEmail(context.Background(), models.VerifyEmailRequest{Token: mail.lastToken(t)}))
+	verified, _ := store.Users().FindByID(user.ID)
+	if assert.NotNil(t, verified.EmailVerifiedAt) {
+		assert.Equal(t, accountNow, *verified.EmailVerifiedAt)
+	}
+
+	err = accounts.SendVerification(context.Background(), verified)
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonAlreadyVerified, ruleErr.Reason)
+	}
+}
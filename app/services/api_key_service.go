@@ -0,0 +1,176 @@
+// app/services/api_key_service.go
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+)
+
+const (
+	// apiKeyPrefixBytes is the randomness in the public part of a key, which tells keys apart
+	apiKeyPrefixBytes = 6
+	// apiKeySecretBytes is the randomness in the secret part of a key
+	apiKeySecretBytes = 32
+	// apiKeyTouchInterval is how often the last use of a key is written at most, so busy
+	// clients do not write to the database on every request
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyPrefixLength is the length of the prefix stored with every key, e.g. nwk_0123456789ab
+var apiKeyPrefixLength = len(models.APIKeyPrefix) + 2*apiKeyPrefixBytes
+
+// MaxAPIKeysPerUser caps the usable keys of a user, revoked and expired keys aside
+const MaxAPIKeysPerUser = 10
+
+// APIKeyService creates, authenticates and revokes the API keys machine clients use instead of a JWT
+type APIKeyService struct {
+	Store     repositories.Store
+	Validator *validation.Validator
+	Clock     clock.Clock
+}
+
+func NewAPIKeyService(store repositories.Store, validator *validation.Validator, clk clock.Clock) *APIKeyService {
+	return &APIKeyService{Store: store, Validator: validator, Clock: clk}
+}
+
+// Create issues a key acting as owner and returns it along with the whole key, which is not stored
+// and cannot be shown again. Only admins can hold keys with the admin scope. Invalid requests fail
+// with validation.Errors.
+func (s *APIKeyService) Create(ctx context.Context, owner models.User, request models.CreateAPIKeyRequest) (models.APIKey, string, error) {
+	if err := s.Validator.Struct(ctx, request); err != nil {
+		return models.APIKey{}, "", err
+	}
+	now := s.Clock.Now()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return models.APIKey{}, "", ruleError(ReasonInvalidRequest, "The expiry date must be in the future")
+	}
+	for _, scope := range request.Scopes {
+		if scope == models.ScopeAdmin && owner.Role != RoleAdmin {
+			return models.APIKey{}, "", ruleError(ReasonForbidden, "Only the keys of admins can have the admin scope")
+		}
+	}
+
+	prefix := make([]byte, apiKeyPrefixBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return models.APIKey{}, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return models.APIKey{}, "", err
+	}
+	apiKey := models.APIKey{
+		UserID:    owner.ID,
+		Name:      request.Name,
+		Prefix:    models.APIKeyPrefix + hex.EncodeToString(prefix),
+		Scopes:    strings.Join(request.Scopes, ","),
+		ExpiresAt: request.ExpiresAt,
+	}
+	key := apiKey.Prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	apiKey.KeyHash = hashToken(key)
+
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		usable, err := store.APIKeys().CountUsable(owner.ID, now)
+		if err != nil {
+			return err
+		}
+		if usable >= MaxAPIKeysPerUser {
+			return ruleError(ReasonConflict, "A user can have at most %d API keys, revoke one first", MaxAPIKeysPerUser)
+		}
+		return store.APIKeys().Create(&apiKey)
+	})
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+// CreateFor issues a key acting as the user with the given ID, which an admin asked for
+func (s *APIKeyService) CreateFor(ctx context.Context, userID uint, request models.CreateAPIKeyRequest) (models.APIKey, string, error) {
+	owner, err := s.Store.WithContext(ctx).Users().FindByID(userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.APIKey{}, "", ruleError(ReasonNotFound, "User not found")
+	}
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	return s.Create(ctx, owner, request)
+}
+
+// Authenticate returns the key sent by a client from ip and the user it acts as, recording the use.
+// Unknown, revoked and expired keys, and the keys of deleted users, fail with ReasonInvalidToken.
+func (s *APIKeyService) Authenticate(ctx context.Context, key, ip string) (models.APIKey, models.User, error) {
+	invalid := ruleError(ReasonInvalidToken, "Invalid, expired or revoked API key")
+	if !strings.HasPrefix(key, models.APIKeyPrefix) || len(key) <= apiKeyPrefixLength || key[apiKeyPrefixLength] != '_' {
+		return models.APIKey{}, models.User{}, invalid
+	}
+
+	store := s.Store.WithContext(ctx)
+	apiKey, err := store.APIKeys().FindByPrefix(key[:apiKeyPrefixLength])
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.APIKey{}, models.User{}, invalid
+	}
+	if err != nil {
+		return models.APIKey{}, models.User{}, err
+	}
+	now := s.Clock.Now()
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashToken(key))) != 1 || !apiKey.Usable(now) {
+		return models.APIKey{}, models.User{}, invalid
+	}
+
+	user, err := store.Users().FindByID(apiKey.UserID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.APIKey{}, models.User{}, invalid
+	}
+	if err != nil {
+		return models.APIKey{}, models.User{}, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval || apiKey.LastUsedIP != ip {
+		if err := store.APIKeys().Touch(apiKey.ID, now, ip); err != nil {
+			return models.APIKey{}, models.User{}, err
+		}
+		apiKey.LastUsedAt, apiKey.LastUsedIP = &now, ip
+	}
+	return apiKey, user, nil
+}
+
+// List returns the keys of userID, or of every user when userID is 0, newest first
+func (s *APIKeyService) List(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	return s.Store.WithContext(ctx).APIKeys().List(userID)
+}
+
+// Revoke stops the key with the given ID from working. Unless ownerID is 0, as it is for admins,
+// only the keys of ownerID can be revoked and the others are reported as not found.
+func (s *APIKeyService) Revoke(ctx context.Context, id, ownerID uint) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var err error
+		apiKey, err = store.APIKeys().FindByID(id)
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && ownerID != 0 && apiKey.UserID != ownerID) {
+			return ruleError(ReasonNotFound, "API key not found")
+		}
+		if err != nil {
+			return err
+		}
+		if apiKey.RevokedAt != nil {
+			return ruleError(ReasonConflict, "API key is already revoked")
+		}
+
+		now := s.Clock.Now()
+		apiKey.RevokedAt = &now
+		return store.APIKeys().Revoke(id, now)
+	})
+	return apiKey, err
+}
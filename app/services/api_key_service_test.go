@@ -0,0 +1,159 @@
+// app/services/api_key_service_test.go
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAPIKeys(t *testing.T, clk clock.Clock) (*services.APIKeyService, *repositories.MemoryStore, models.User) {
+	store := repositories.NewMemoryStore()
+	user := models.User{Username: "hpcpp", Email: "hpcpp@example.com", Role: services.RoleUser}
+	if err := store.Users().Create(&user); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	return services.NewAPIKeyService(store, validation.New(store, config.Default().Password), clk), store, user
+}
+
+func assertInvalidKey(t *testing.T, err error) {
+	t.Helper()
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidToken, ruleErr.Reason)
+	}
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	keys, store, user := setupAPIKeys(t, clock.Fixed(accountNow))
+
+	apiKey, key, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{
+		Name:   "Supplies sync",
+		Scopes: []string{models.ScopeRead},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, apiKey.Prefix+"_"))
+
+	// Only the hash is stored
+	stored, err := store.APIKeys().FindByPrefix(apiKey.Prefix)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, stored.KeyHash)
+
+	used, owner, err := keys.Authenticate(context.Background(), key, "10.0.0.7")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, owner.ID)
+	assert.Equal(t, []string{models.ScopeRead}, used.ScopeList())
+	stored, _ = store.APIKeys().FindByID(apiKey.ID)
+	if assert.NotNil(t, stored.LastUsedAt) {
+		assert.Equal(t, accountNow, *stored.LastUsedAt)
+	}
+	assert.Equal(t, "10.0.0.7", stored.LastUsedIP)
+
+	// A key with the right prefix and another secret is not accepted
+	_, _, err = keys.Authenticate(context.Background(), apiKey.Prefix+"_forged", "10.0.0.7")
+	assertInvalidKey(t, err)
+	_, _, err = keys.Authenticate(context.Background(), "not-a-key", "10.0.0.7")
+	assertInvalidKey(t, err)
+
+	// Users only revoke their own keys
+	_, err = keys.Revoke(context.Background(), apiKey.ID, user.ID+100)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+	revoked, err := keys.Revoke(context.Background(), apiKey.ID, user.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	_, _, err = keys.Authenticate(context.Background(), key, "10.0.0.7")
+	assertInvalidKey(t, err)
+
+	listed, err := keys.List(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
+
+func TestAPIKeysExpire(t *testing.T) {
+	keys, _, user := setupAPIKeys(t, clock.Fixed(accountNow))
+
+	_, _, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{
+		Name:      "Expired already",
+		Scopes:    []string{models.ScopeRead},
+		ExpiresAt: &accountNow,
+	})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidRequest, ruleErr.Reason)
+	}
+
+	expiresAt := accountNow.Add(24 * time.Hour)
+	_, key, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{
+		Name:      "Nightly report",
+		Scopes:    []string{models.ScopeRead},
+		ExpiresAt: &expiresAt,
+	})
+	assert.NoError(t, err)
+	_, _, err = keys.Authenticate(context.Background(), key, "10.0.0.7")
+	assert.NoError(t, err)
+
+	keys.Clock = clock.Fixed(expiresAt)
+	_, _, err = keys.Authenticate(context.Background(), key, "10.0.0.7")
+	assertInvalidKey(t, err)
+}
+
+func TestCreateAPIKeyRules(t *testing.T) {
+	keys, store, user := setupAPIKeys(t, clock.Fixed(accountNow))
+
+	_, _, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "Ops", Scopes: []string{models.ScopeAdmin}})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+	}
+
+	_, _, err = keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "Ops", Scopes: []string{"read", "read"}})
+	fieldErrs, ok := validation.AsErrors(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, validation.Errors{{Field: "scopes", Rule: "unique", Message: "must not repeat the same value"}}, fieldErrs)
+	}
+	_, _, err = keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "Ops", Scopes: []string{"delete"}})
+	fieldErrs, ok = validation.AsErrors(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "oneof", fieldErrs[0].Rule)
+	}
+
+	for i := 0; i < services.MaxAPIKeysPerUser; i++ {
+		_, _, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "Worker", Scopes: []string{models.ScopeWrite}})
+		assert.NoError(t, err)
+	}
+	_, _, err = keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "One too many", Scopes: []string{models.ScopeWrite}})
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+
+	// Revoking a key makes room for another
+	listed, err := keys.List(context.Background(), user.ID)
+	assert.NoError(t, err)
+	_, err = keys.Revoke(context.Background(), listed[0].ID, 0)
+	assert.NoError(t, err)
+	_, key, err := keys.Create(context.Background(), user, models.CreateAPIKeyRequest{Name: "Replacement", Scopes: []string{models.ScopeWrite}})
+	assert.NoError(t, err)
+
+	// The keys of deleted users stop working with them
+	assert.NoError(t, store.Users().Delete(&user))
+	_, _, err = keys.Authenticate(context.Background(), key, "10.0.0.7")
+	assertInvalidKey(t, err)
+	_, _, err = keys.CreateFor(context.Background(), user.ID, models.CreateAPIKeyRequest{Name: "Spare", Scopes: []string{models.ScopeRead}})
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+}
@@ -0,0 +1,195 @@
+// app/services/checkout_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+)
+
+// CheckoutService turns the items picked by a buyer into a paid order
+type CheckoutService struct {
+	Store repositories.Store
+	Clock clock.Clock
+}
+
+func NewCheckoutService(store repositories.Store, clk clock.Clock) *CheckoutService {
+	return &CheckoutService{Store: store, Clock: clk}
+}
+
+// AvailableOffers lists the offers with the stock held by active reservations subtracted
+func (s *CheckoutService) AvailableOffers(ctx context.Context) ([]models.Offer, error) {
+	store := s.Store.WithContext(ctx)
+	offers, err := store.Offers().List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock held by active reservations is not available to other buyers
+	reserved, err := store.Reservations().ReservedQuantities(s.Clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range offers {
+		offers[i].Quantity -= reserved[offers[i].ID]
+		if offers[i].Quantity < 0 {
+			offers[i].Quantity = 0
+		}
+	}
+	return offers, nil
+}
+
+// Checkout creates the order of buyer, charging their credits unless it is placed on behalf of a community
+func (s *CheckoutService) Checkout(ctx context.Context, buyer models.User, request models.CheckoutRequest) (models.Order, error) {
+	order, err := s.checkout(ctx, buyer, request)
+	metrics.CheckoutOutcomes.WithLabelValues(checkoutOutcome(err)).Inc()
+	return order, err
+}
+
+// checkoutOutcome labels the result of a checkout: success, the rule it broke or db_error
+func checkoutOutcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	if ruleErr, ok := AsRuleError(err); ok {
+		return ruleErr.Reason
+	}
+	return "db_error"
+}
+
+func (s *CheckoutService) checkout(ctx context.Context, buyer models.User, request models.CheckoutRequest) (models.Order, error) {
+	var order models.Order
+
+	// Orders placed on behalf of a community require the user to be one of its representatives
+	if request.CommunityID != nil {
+		isRepresentative, err := s.Store.WithContext(ctx).Communities().IsRepresentative(*request.CommunityID, buyer.ID)
+		if err != nil {
+			return order, err
+		}
+		if !isRepresentative {
+			return order, ruleError(ReasonForbidden, "You are not a representative of this community")
+		}
+	}
+
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var err error
+		order, err = s.placeOrder(store, buyer, request, nil)
+		return err
+	})
+	return order, err
+}
+
+// placeOrder creates the order inside the transaction of store, taking its items out of stock.
+// held is the stock the buyer already holds in a reservation, which is available to this order.
+func (s *CheckoutService) placeOrder(store repositories.Store, buyer models.User, request models.CheckoutRequest, held map[uint]int) (models.Order, error) {
+	now := s.Clock.Now()
+
+	offers, err := lockOffers(store, request.Items)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	// Stock held by other buyers' reservations is not available. It is read once the offers
+	// are locked, so reservations and checkouts of the same offers cannot interleave.
+	reserved, err := store.Reservations().ReservedQuantities(now)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	// Validate availability and calculate total amount
+	var totalAmount float64
+	var orderItems []models.OrderItem
+	for _, item := range request.Items {
+		offer := offers[item.OfferID]
+		if offer.Quantity-reserved[offer.ID]+held[offer.ID] < item.Quantity {
+			return models.Order{}, ruleError(ReasonInsufficientStock, "Not enough quantity for offer ID %d", item.OfferID)
+		}
+		subTotal := float64(item.Quantity) * offer.Price
+		totalAmount += subTotal
+		orderItems = append(orderItems, models.OrderItem{
+			OfferID:  item.OfferID,
+			Quantity: item.Quantity,
+			SubTotal: subTotal,
+		})
+	}
+
+	// Enforce per-user rationing rules before the order is persisted.
+	// Trade orders of a community are not rations of the representative placing them.
+	if request.CommunityID == nil {
+		// Lock the buyer so their concurrent checkouts of different offers in the same
+		// rationed category count each other's orders instead of both passing the limit
+		if _, err := store.Users().FindForUpdate(buyer.ID); err != nil {
+			return models.Order{}, err
+		}
+		if err := checkRationing(store, buyer.ID, orderItems, offers, now); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	order := models.Order{
+		UserID:        buyer.ID,
+		CommunityID:   request.CommunityID,
+		Status:        models.OrderProcessing,
+		OrderItems:    orderItems,
+		TotalAmount:   totalAmount,
+		PaymentStatus: models.PaymentPaid,
+	}
+	if request.CommunityID != nil {
+		// The community settles the trade later, so the order is added to its debt
+		order.PaymentStatus = models.PaymentOnAccount
+	}
+	if err := store.Orders().Create(&order); err != nil {
+		return order, err
+	}
+
+	// Pay for the order with the buyer's credits
+	if order.PaymentStatus == models.PaymentPaid {
+		err := store.Wallets().Debit(buyer.ID, totalAmount, &order.ID, fmt.Sprintf("Payment for order %d", order.ID))
+		if errors.Is(err, repositories.ErrInsufficientCredits) {
+			return order, ruleError(ReasonInsufficientCredits, "Insufficient credits to pay for the order")
+		}
+		if err != nil {
+			return order, err
+		}
+	}
+
+	for _, item := range request.Items {
+		if err := store.Offers().AdjustStock(item.OfferID, -item.Quantity); err != nil {
+			return order, err
+		}
+	}
+	return order, nil
+}
+
+// lockOffers loads and locks the offers of items until the end of the transaction.
+// They are locked in ID order so two transactions over the same offers cannot deadlock.
+func lockOffers(store repositories.Store, items []models.CheckoutItem) (map[uint]models.Offer, error) {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.OfferID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	offers := make(map[uint]models.Offer, len(ids))
+	for _, id := range ids {
+		if _, ok := offers[id]; ok {
+			continue
+		}
+		offer, err := store.Offers().FindForUpdate(id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ruleError(ReasonOfferNotFound, "Offer with ID %d not found", id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		offers[id] = offer
+	}
+	return offers, nil
+}
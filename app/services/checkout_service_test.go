@@ -0,0 +1,281 @@
+// app/services/checkout_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStore(t *testing.T) (*repositories.MemoryStore, models.User) {
+	store := repositories.NewMemoryStore()
+	store.AddOffer(models.Offer{ID: 1, Name: "water", Quantity: 10, Price: 1, Category: "drink"})
+	store.AddOffer(models.Offer{ID: 2, Name: "meat", Quantity: 5, Price: 4, Category: "food"})
+
+	buyer := models.User{Username: "buyer", Email: "buyer@example.com", Role: services.RoleUser}
+	if err := store.Users().Create(&buyer); err != nil {
+		t.Fatalf("Failed to create buyer: %s", err)
+	}
+	if err := store.Wallets().Credit(buyer.ID, 20, models.TransactionGrant, nil, "test credits"); err != nil {
+		t.Fatalf("Failed to grant credits: %s", err)
+	}
+	return store, buyer
+}
+
+func offerQuantity(t *testing.T, store repositories.Store, id uint) int {
+	offer, err := store.Offers().FindForUpdate(id)
+	if err != nil {
+		t.Fatalf("Failed to find offer %d: %s", id, err)
+	}
+	return offer.Quantity
+}
+
+func TestCheckoutChargesCreditsAndTakesStock(t *testing.T) {
+	store, buyer := setupStore(t)
+	checkout := services.NewCheckoutService(store, clock.Fixed(time.Now()))
+
+	order, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 3},
+		{OfferID: 2, Quantity: 2},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, 11.0, order.TotalAmount)
+	assert.Equal(t, models.PaymentPaid, order.PaymentStatus)
+	assert.Equal(t, 9.0, store.Balance(buyer.ID))
+	assert.Equal(t, 7, offerQuantity(t, store, 1))
+	assert.Equal(t, 3, offerQuantity(t, store, 2))
+}
+
+func TestCheckoutRejectsReservedStock(t *testing.T) {
+	store, buyer := setupStore(t)
+	store.SetReserved(2, 4)
+	checkout := services.NewCheckoutService(store, clock.Fixed(time.Now()))
+	rejected := testutil.ToFloat64(metrics.CheckoutOutcomes.WithLabelValues(services.ReasonInsufficientStock))
+
+	_, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 2, Quantity: 2}}})
+	ruleErr, ok := services.AsRuleError(err)
+	assert.True(t, ok)
+	assert.Equal(t, services.ReasonInsufficientStock, ruleErr.Reason)
+	assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.CheckoutOutcomes.WithLabelValues(services.ReasonInsufficientStock)))
+}
+
+func TestCheckoutWithoutCreditsLeavesStockUntouched(t *testing.T) {
+	store, buyer := setupStore(t)
+	checkout := services.NewCheckoutService(store, clock.Fixed(time.Now()))
+
+	_, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+		{OfferID: 2, Quantity: 5},
+	}})
+	ruleErr, ok := services.AsRuleError(err)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, services.ReasonInsufficientCredits, ruleErr.Reason)
+	assert.Equal(t, 10, offerQuantity(t, store, 1))
+	assert.Equal(t, 5, offerQuantity(t, store, 2))
+	assert.Equal(t, 20.0, store.Balance(buyer.ID))
+
+	orders, err := store.Orders().ListWithItems()
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestCheckoutEnforcesRationing(t *testing.T) {
+	store, buyer := setupStore(t)
+	store.AddRationingRule(models.RationingRule{Category: "drink", MaxQuantity: 4, WindowHours: 24})
+	checkout := services.NewCheckoutService(store, clock.Fixed(time.Now()))
+
+	_, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 3}}})
+	assert.NoError(t, err)
+
+	_, err = checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 2}}})
+	ruleErr, ok := services.AsRuleError(err)
+	assert.True(t, ok)
+	assert.Equal(t, services.ReasonRationingLimit, ruleErr.Reason)
+}
+
+func TestRationingIgnoresCancelledOrders(t *testing.T) {
+	store, buyer := setupStore(t)
+	store.AddRationingRule(models.RationingRule{Category: "drink", MaxQuantity: 4, WindowHours: 24})
+	now := clock.Fixed(time.Now())
+	checkout := services.NewCheckoutService(store, now)
+
+	order, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 4}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = services.NewOrderService(store, now).CancelByBuyer(context.Background(), buyer.ID, order.ID)
+	assert.NoError(t, err)
+
+	// The cancelled units were returned, so the buyer may order them again
+	_, err = checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 4}}})
+	assert.NoError(t, err)
+}
+
+// lockOrderStore records when a checkout locks a user and when it reads their rationed purchases
+type lockOrderStore struct {
+	*repositories.MemoryStore
+	calls *[]string
+}
+
+func (s lockOrderStore) WithContext(ctx context.Context) repositories.Store { return s }
+
+func (s lockOrderStore) Transaction(fn func(repositories.Store) error) error {
+	return s.MemoryStore.Transaction(func(repositories.Store) error { return fn(s) })
+}
+
+func (s lockOrderStore) Users() repositories.UserRepository {
+	return lockOrderUsers{s.MemoryStore.Users(), s.calls}
+}
+
+func (s lockOrderStore) Rationing() repositories.RationingRepository {
+	return lockOrderRationing{s.MemoryStore.Rationing(), s.calls}
+}
+
+type lockOrderUsers struct {
+	repositories.UserRepository
+	calls *[]string
+}
+
+func (r lockOrderUsers) FindForUpdate(id uint) (models.User, error) {
+	*r.calls = append(*r.calls, "lock user")
+	return r.UserRepository.FindForUpdate(id)
+}
+
+type lockOrderRationing struct {
+	repositories.RationingRepository
+	calls *[]string
+}
+
+func (r lockOrderRationing) Bought(userID uint, rule models.RationingRule, since time.Time) (int, error) {
+	*r.calls = append(*r.calls, "count bought")
+	return r.RationingRepository.Bought(userID, rule, since)
+}
+
+func TestRationingLocksBuyerBeforeCounting(t *testing.T) {
+	memory, buyer := setupStore(t)
+	memory.AddRationingRule(models.RationingRule{Category: "drink", MaxQuantity: 4, WindowHours: 24})
+	var calls []string
+	checkout := services.NewCheckoutService(lockOrderStore{memory, &calls}, clock.Fixed(time.Now()))
+
+	_, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 2}}})
+	assert.NoError(t, err)
+
+	// Concurrent checkouts of the same buyer wait on the lock, so each counts the other's order
+	assert.Equal(t, []string{"lock user", "count bought"}, calls)
+}
+
+func TestCommunityCheckoutRequiresRepresentative(t *testing.T) {
+	store, buyer := setupStore(t)
+	checkout := services.NewCheckoutService(store, clock.Fixed(time.Now()))
+	communityID := uint(7)
+
+	_, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{
+		Items:       []models.CheckoutItem{{OfferID: 2, Quantity: 5}},
+		CommunityID: &communityID,
+	})
+	ruleErr, ok := services.AsRuleError(err)
+	assert.True(t, ok)
+	assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+
+	// Representatives order on account, so the buyer's credits are not charged
+	store.AddRepresentative(communityID, buyer.ID)
+	order, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{
+		Items:       []models.CheckoutItem{{OfferID: 2, Quantity: 5}},
+		CommunityID: &communityID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentOnAccount, order.PaymentStatus)
+	assert.Equal(t, 20.0, store.Balance(buyer.ID))
+}
+
+func TestCancelRefundsAndRestocks(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := clock.Fixed(time.Now())
+	checkout := services.NewCheckoutService(store, now)
+	orders := services.NewOrderService(store, now)
+
+	order, err := checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 2, Quantity: 2}}})
+	assert.NoError(t, err)
+
+	_, err = orders.CancelByBuyer(context.Background(), buyer.ID+1, order.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	assert.True(t, ok)
+	assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+
+	cancelled, err := orders.CancelByBuyer(context.Background(), buyer.ID, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderCancelled, cancelled.Status)
+	assert.Equal(t, models.PaymentRefunded, cancelled.PaymentStatus)
+	assert.Equal(t, 20.0, store.Balance(buyer.ID))
+	assert.Equal(t, 5, offerQuantity(t, store, 2))
+
+	_, err = orders.UpdateStatus(context.Background(), order.ID, models.OrderProcessing)
+	ruleErr, ok = services.AsRuleError(err)
+	assert.True(t, ok)
+	assert.Equal(t, services.ReasonInvalidTransition, ruleErr.Reason)
+}
+
+func TestFindOrderForCaller(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := clock.Fixed(time.Now())
+	orders := services.NewOrderService(store, now)
+	order, err := services.NewCheckoutService(store, now).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 2, Quantity: 2}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	found, err := orders.FindFor(context.Background(), buyer, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+
+	admin := models.User{Username: "admin", Email: "admin@example.com", Role: services.RoleAdmin}
+	found, err = orders.FindFor(context.Background(), admin, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+
+	// Orders of other buyers look the same as missing ones
+	other := models.User{Username: "other", Email: "other@example.com", Role: services.RoleUser}
+	if err := store.Users().Create(&other); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	for _, id := range []uint{order.ID, order.ID + 100} {
+		_, err = orders.FindFor(context.Background(), other, id)
+		ruleErr, ok := services.AsRuleError(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+		}
+	}
+}
+
+func TestShippingRequiresDelivery(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := clock.Fixed(time.Now())
+	order, err := services.NewCheckoutService(store, now).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 1}}})
+	assert.NoError(t, err)
+	orders := services.NewOrderService(store, now)
+
+	_, err = orders.UpdateStatus(context.Background(), order.ID, models.OrderDelivered)
+	_, ok := services.AsRuleError(err)
+	assert.True(t, ok)
+
+	_, err = orders.UpdateStatus(context.Background(), order.ID, models.OrderShipped)
+	_, ok = services.AsRuleError(err)
+	assert.True(t, ok)
+
+	store.AddDelivery(order.ID)
+	updated, err := orders.UpdateStatus(context.Background(), order.ID, models.OrderShipped)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderShipped, updated.Status)
+}
@@ -0,0 +1,105 @@
+// app/services/community_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+)
+
+// CommunityService manages the communities the refuge trades with and what they owe
+type CommunityService struct {
+	Store repositories.Store
+}
+
+func NewCommunityService(store repositories.Store) *CommunityService {
+	return &CommunityService{Store: store}
+}
+
+// List returns every community with its representatives
+func (s *CommunityService) List(ctx context.Context) ([]models.Community, error) {
+	return s.Store.WithContext(ctx).Communities().List()
+}
+
+// Create registers the community described by request. Its name must be free, which the unique
+// index checks so two admins creating the same community at once cannot both succeed.
+func (s *CommunityService) Create(ctx context.Context, request models.CommunityRequest) (models.Community, error) {
+	community := models.Community{
+		Name:     request.Name,
+		Location: request.Location,
+	}
+	err := s.Store.WithContext(ctx).Communities().Create(&community)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return community, ruleError(ReasonConflict, "community already exists")
+	}
+	return community, err
+}
+
+// AddRepresentative allows userID to place orders on behalf of communityID and returns the community
+func (s *CommunityService) AddRepresentative(ctx context.Context, communityID, userID uint) (models.Community, error) {
+	store := s.Store.WithContext(ctx)
+	if _, err := findCommunity(store, communityID); err != nil {
+		return models.Community{}, err
+	}
+
+	_, err := store.Users().FindByID(userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.Community{}, ruleError(ReasonInvalidRequest, "User not found")
+	}
+	if err != nil {
+		return models.Community{}, err
+	}
+
+	if err := store.Communities().AddRepresentative(communityID, userID); err != nil {
+		return models.Community{}, err
+	}
+	return store.Communities().FindByID(communityID)
+}
+
+// RemoveRepresentative revokes the right of userID to order on behalf of communityID
+func (s *CommunityService) RemoveRepresentative(ctx context.Context, communityID, userID uint) error {
+	store := s.Store.WithContext(ctx)
+	if _, err := findCommunity(store, communityID); err != nil {
+		return err
+	}
+	return store.Communities().RemoveRepresentative(communityID, userID)
+}
+
+// RecordSettlement records what communityID handed over to pay its trade debt
+func (s *CommunityService) RecordSettlement(ctx context.Context, communityID uint, request models.SettlementRequest) (models.CommunitySettlement, error) {
+	store := s.Store.WithContext(ctx)
+	if _, err := findCommunity(store, communityID); err != nil {
+		return models.CommunitySettlement{}, err
+	}
+
+	settlement := models.CommunitySettlement{
+		CommunityID: communityID,
+		Amount:      request.Amount,
+		Description: request.Description,
+	}
+	err := store.Communities().CreateSettlement(&settlement)
+	return settlement, err
+}
+
+// TradeBalances reports what every community ordered, received and still owes
+func (s *CommunityService) TradeBalances(ctx context.Context) ([]models.CommunityTradeBalance, error) {
+	balances, err := s.Store.WithContext(ctx).Communities().TradeBalances()
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		balances[i].Balance = balances[i].OrderedAmount - balances[i].SettledAmount
+	}
+	return balances, nil
+}
+
+func findCommunity(store repositories.Store, id uint) (models.Community, error) {
+	community, err := store.Communities().FindByID(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return community, ruleError(ReasonNotFound, "Community not found")
+	}
+	return community, err
+}
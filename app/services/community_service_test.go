@@ -0,0 +1,53 @@
+// app/services/community_service_test.go
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCommunityRejectsTakenName(t *testing.T) {
+	store, _ := setupStore(t)
+	communities := services.NewCommunityService(store)
+
+	community, err := communities.Create(context.Background(), models.CommunityRequest{Name: "Riverside", Location: "East bank"})
+	assert.NoError(t, err)
+	assert.NotZero(t, community.ID)
+
+	_, err = communities.Create(context.Background(), models.CommunityRequest{Name: "Riverside"})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+}
+
+func TestCommunitySummaryHidesRepresentativePasswords(t *testing.T) {
+	store, buyer := setupStore(t)
+	communities := services.NewCommunityService(store)
+	community, err := communities.Create(context.Background(), models.CommunityRequest{Name: "Riverside"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	community, err = communities.AddRepresentative(context.Background(), community.ID, buyer.ID)
+	assert.NoError(t, err)
+	summary := community.Summary()
+	if assert.Len(t, summary.Representatives, 1) {
+		assert.Equal(t, buyer.Username, summary.Representatives[0].Username)
+	}
+
+	body, err := json.Marshal(models.CommunityResponse{Code: 200, Message: summary})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "password")
+
+	_, err = communities.AddRepresentative(context.Background(), community.ID, buyer.ID+1)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInvalidRequest, ruleErr.Reason)
+	}
+}
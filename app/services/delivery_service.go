@@ -0,0 +1,119 @@
+// app/services/delivery_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+)
+
+// DeliveryService opens delivery slots and schedules the delivery of orders in them
+type DeliveryService struct {
+	Store repositories.Store
+	Clock clock.Clock
+}
+
+func NewDeliveryService(store repositories.Store, clk clock.Clock) *DeliveryService {
+	return &DeliveryService{Store: store, Clock: clk}
+}
+
+// Slots lists the delivery slots that have not ended yet
+func (s *DeliveryService) Slots(ctx context.Context) ([]models.DeliverySlot, error) {
+	return s.Store.WithContext(ctx).Deliveries().UpcomingSlots(s.Clock.Now())
+}
+
+// CreateSlot opens the delivery slot described by request
+func (s *DeliveryService) CreateSlot(ctx context.Context, request models.DeliverySlotRequest) (models.DeliverySlot, error) {
+	slot := models.DeliverySlot{
+		StartsAt: request.StartsAt,
+		EndsAt:   request.EndsAt,
+		Capacity: request.Capacity,
+	}
+	err := s.Store.WithContext(ctx).Deliveries().CreateSlot(&slot)
+	return slot, err
+}
+
+// Assign schedules the delivery of orderID, or moves it when it already was
+func (s *DeliveryService) Assign(ctx context.Context, orderID uint, request models.AssignDeliveryRequest) (models.Delivery, error) {
+	var delivery models.Delivery
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		order, err := store.Orders().FindByID(orderID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "Order not found")
+		}
+		if err != nil {
+			return err
+		}
+		if order.Status == models.OrderCancelled || order.Status == models.OrderDelivered {
+			return ruleError(ReasonInvalidTransition, "Delivery cannot be scheduled for a %s order", order.Status)
+		}
+
+		_, err = store.Users().FindByID(request.CourierID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonInvalidRequest, "Courier not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Lock the slot so two assignments cannot both take its last place
+		slot, err := store.Deliveries().FindSlotForUpdate(request.SlotID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonInvalidRequest, "Delivery slot not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		booked, err := store.Deliveries().CountBooked(slot.ID, order.ID)
+		if err != nil {
+			return err
+		}
+		if int(booked) >= slot.Capacity {
+			return ruleError(ReasonConflict, "Delivery slot is full")
+		}
+
+		delivery, err = store.Deliveries().FindByOrder(order.ID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			delivery = models.Delivery{OrderID: order.ID}
+		} else if err != nil {
+			return err
+		}
+		delivery.SlotID = slot.ID
+		delivery.Slot = slot
+		delivery.CourierID = request.CourierID
+		delivery.Destination = request.Destination
+		delivery.Notes = request.Notes
+		return store.Deliveries().Save(&delivery)
+	})
+	return delivery, err
+}
+
+// ForBuyer returns the delivery of an order placed by buyerID
+func (s *DeliveryService) ForBuyer(ctx context.Context, buyerID, orderID uint) (models.Delivery, error) {
+	store := s.Store.WithContext(ctx)
+	order, err := store.Orders().FindByID(orderID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && order.UserID != buyerID) {
+		return models.Delivery{}, ruleError(ReasonNotFound, "Delivery not scheduled")
+	}
+	if err != nil {
+		return models.Delivery{}, err
+	}
+
+	delivery, err := store.Deliveries().FindByOrder(orderID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return delivery, ruleError(ReasonNotFound, "Delivery not scheduled")
+	}
+	return delivery, err
+}
+
+// ForCourier lists the deliveries of courierID in the slots starting on the same day as day
+func (s *DeliveryService) ForCourier(ctx context.Context, courierID uint, day time.Time) ([]models.Delivery, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return s.Store.WithContext(ctx).Deliveries().ForCourier(courierID, start, start.AddDate(0, 0, 1))
+}
@@ -0,0 +1,106 @@
+// app/services/delivery_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignDeliveryRespectsSlotCapacity(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	deliveries := services.NewDeliveryService(store, clock.Fixed(now))
+	slot, err := deliveries.CreateSlot(context.Background(), models.DeliverySlotRequest{
+		StartsAt: now.Add(time.Hour),
+		EndsAt:   now.Add(3 * time.Hour),
+		Capacity: 1,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	first := orderWithStatus(t, store, buyer, models.OrderProcessing)
+	request := models.AssignDeliveryRequest{SlotID: slot.ID, CourierID: buyer.ID, Destination: "North gate"}
+	delivery, err := deliveries.Assign(context.Background(), first.ID, request)
+	assert.NoError(t, err)
+	assert.Equal(t, slot.ID, delivery.SlotID)
+
+	// Moving the same order again does not count its own place against the slot
+	request.Destination = "South gate"
+	delivery, err = deliveries.Assign(context.Background(), first.ID, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "South gate", delivery.Destination)
+
+	second := orderWithStatus(t, store, buyer, models.OrderProcessing)
+	_, err = deliveries.Assign(context.Background(), second.ID, request)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+
+	courierDeliveries, err := deliveries.ForCourier(context.Background(), buyer.ID, now)
+	assert.NoError(t, err)
+	assert.Len(t, courierDeliveries, 1)
+	courierDeliveries, err = deliveries.ForCourier(context.Background(), buyer.ID, now.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Empty(t, courierDeliveries)
+}
+
+func TestAssignDeliveryRejectsInvalidRequests(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	deliveries := services.NewDeliveryService(store, clock.Fixed(now))
+	slot, err := deliveries.CreateSlot(context.Background(), models.DeliverySlotRequest{
+		StartsAt: now.Add(time.Hour),
+		EndsAt:   now.Add(2 * time.Hour),
+		Capacity: 5,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	order := orderWithStatus(t, store, buyer, models.OrderProcessing)
+	cancelled := orderWithStatus(t, store, buyer, models.OrderCancelled)
+
+	tests := []struct {
+		name    string
+		orderID uint
+		request models.AssignDeliveryRequest
+		reason  string
+	}{
+		{"unknown order", order.ID + 100, models.AssignDeliveryRequest{SlotID: slot.ID, CourierID: buyer.ID}, services.ReasonNotFound},
+		{"cancelled order", cancelled.ID, models.AssignDeliveryRequest{SlotID: slot.ID, CourierID: buyer.ID}, services.ReasonInvalidTransition},
+		{"unknown courier", order.ID, models.AssignDeliveryRequest{SlotID: slot.ID, CourierID: buyer.ID + 100}, services.ReasonInvalidRequest},
+		{"unknown slot", order.ID, models.AssignDeliveryRequest{SlotID: slot.ID + 100, CourierID: buyer.ID}, services.ReasonInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := deliveries.Assign(context.Background(), tt.orderID, tt.request)
+			ruleErr, ok := services.AsRuleError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.reason, ruleErr.Reason)
+			}
+		})
+	}
+}
+
+func TestDeliveryForBuyerHidesOtherOrders(t *testing.T) {
+	store, buyer := setupStore(t)
+	order := orderWithStatus(t, store, buyer, models.OrderProcessing)
+	deliveries := services.NewDeliveryService(store, clock.Fixed(time.Now()))
+
+	delivery, err := deliveries.ForBuyer(context.Background(), buyer.ID, order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, delivery.OrderID)
+
+	_, err = deliveries.ForBuyer(context.Background(), buyer.ID+1, order.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+}
@@ -0,0 +1,46 @@
+// app/services/errors.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons identify which business rule a request broke
+const (
+	ReasonInvalidRequest      = "invalid_request"
+	ReasonNotFound            = "not_found"
+	ReasonConflict            = "conflict"
+	ReasonForbidden           = "forbidden"
+	ReasonOfferNotFound       = "offer_not_found"
+	ReasonInsufficientStock   = "insufficient_stock"
+	ReasonRationingLimit      = "rationing_limit"
+	ReasonInsufficientCredits = "insufficient_credits"
+	ReasonInvalidTransition   = "invalid_transition"
+	ReasonInvalidToken        = "invalid_token"
+	ReasonInvalidCredentials  = "invalid_credentials"
+	ReasonAlreadyVerified     = "already_verified"
+)
+
+// RuleError is returned when a request breaks a business rule, as opposed to
+// failures of the database or other infrastructure
+type RuleError struct {
+	Reason  string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+func ruleError(reason, format string, args ...interface{}) *RuleError {
+	return &RuleError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// AsRuleError returns the business rule broken by err, if any
+func AsRuleError(err error) (*RuleError, bool) {
+	var ruleErr *RuleError
+	ok := errors.As(err, &ruleErr)
+	return ruleErr, ok
+}
@@ -0,0 +1,151 @@
+// app/services/order_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+)
+
+// OrderService moves orders through their lifecycle once they are placed
+type OrderService struct {
+	Store repositories.Store
+	Clock clock.Clock
+}
+
+func NewOrderService(store repositories.Store, clk clock.Clock) *OrderService {
+	return &OrderService{Store: store, Clock: clk}
+}
+
+// Dashboard lists every order with its items
+func (s *OrderService) Dashboard(ctx context.Context) ([]models.Order, error) {
+	return s.Store.WithContext(ctx).Orders().ListWithItems()
+}
+
+// FindFor returns the order with the given ID when caller placed it or is an admin. Orders of
+// other buyers are reported as not found, so their IDs cannot be probed.
+func (s *OrderService) FindFor(ctx context.Context, caller models.User, id uint) (models.Order, error) {
+	order, err := s.Store.WithContext(ctx).Orders().FindByID(id)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && order.UserID != caller.ID && caller.Role != RoleAdmin) {
+		return models.Order{}, ruleError(ReasonNotFound, "Order not found")
+	}
+	return order, err
+}
+
+// CancelByBuyer cancels an order of buyerID that has not left the refuge yet
+func (s *OrderService) CancelByBuyer(ctx context.Context, buyerID, orderID uint) (models.Order, error) {
+	var order models.Order
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var err error
+		order, err = store.Orders().FindForUpdate(orderID)
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && order.UserID != buyerID) {
+			return ruleError(ReasonNotFound, "Order not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Buyers can only cancel orders that have not left the refuge yet
+		if order.Status != models.OrderProcessing && order.Status != models.OrderPreparing {
+			return ruleError(ReasonInvalidTransition, "Order cannot be cancelled while %s", order.Status)
+		}
+		return cancel(store, &order)
+	})
+	return order, err
+}
+
+// orderTransitions lists the statuses an order can move to from each status. Orders can go back
+// and forth between processing and preparing until they leave the refuge, and cannot be cancelled
+// once shipped. Cancelled orders already returned their stock and credits and delivered orders
+// were handed over, so neither moves again.
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderProcessing: {models.OrderPreparing, models.OrderShipped, models.OrderCancelled},
+	models.OrderPreparing:  {models.OrderProcessing, models.OrderShipped, models.OrderCancelled},
+	models.OrderShipped:    {models.OrderDelivered},
+	models.OrderDelivered:  {},
+	models.OrderCancelled:  {},
+}
+
+// canTransition reports whether an order can move from one status to another
+func canTransition(from, to models.OrderStatus) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateStatus moves an order to status on behalf of an administrator
+func (s *OrderService) UpdateStatus(ctx context.Context, orderID uint, status models.OrderStatus) (models.Order, error) {
+	var order models.Order
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var err error
+		order, err = store.Orders().FindForUpdate(orderID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "Order not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if !canTransition(order.Status, status) {
+			return ruleError(ReasonInvalidTransition, "Order cannot go from %s to %s", order.Status, status)
+		}
+
+		// Cancelling returns the items to stock and refunds the buyer
+		if status == models.OrderCancelled {
+			return cancel(store, &order)
+		}
+
+		// Shipping requires a scheduled delivery
+		if status == models.OrderShipped {
+			_, err := store.Deliveries().FindByOrder(order.ID)
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ruleError(ReasonInvalidTransition, "order has no delivery scheduled")
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		order.Status = status
+		if err := store.Orders().UpdateStatus(&order); err != nil {
+			return err
+		}
+		if order.Status == models.OrderDelivered {
+			return store.Deliveries().MarkDelivered(order.ID, s.Clock.Now())
+		}
+		return nil
+	})
+	return order, err
+}
+
+// cancel marks the order as cancelled, returns its items to stock and refunds the buyer
+func cancel(store repositories.Store, order *models.Order) error {
+	items, err := store.Orders().Items(order.ID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := store.Offers().AdjustStock(item.OfferID, item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	if order.PaymentStatus == models.PaymentPaid && order.UserID != 0 {
+		if err := store.Wallets().Credit(order.UserID, order.TotalAmount, models.TransactionRefund, &order.ID,
+			fmt.Sprintf("Refund for cancelled order %d", order.ID)); err != nil {
+			return err
+		}
+		order.PaymentStatus = models.PaymentRefunded
+	}
+
+	order.Status = models.OrderCancelled
+	return store.Orders().UpdateStatus(order)
+}
@@ -0,0 +1,100 @@
+// app/services/order_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// orderWithStatus places an order of 2 meat for buyer and moves it straight to status
+func orderWithStatus(t *testing.T, store *repositories.MemoryStore, buyer models.User, status models.OrderStatus) models.Order {
+	order, err := services.NewCheckoutService(store, clock.Fixed(time.Now())).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 2, Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("Failed to place order: %s", err)
+	}
+	order.Status = status
+	if err := store.Orders().UpdateStatus(&order); err != nil {
+		t.Fatalf("Failed to move order to %s: %s", status, err)
+	}
+	store.AddDelivery(order.ID)
+	return order
+}
+
+func TestUpdateStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to models.OrderStatus
+		allowed  bool
+	}{
+		{models.OrderProcessing, models.OrderPreparing, true},
+		{models.OrderPreparing, models.OrderProcessing, true},
+		{models.OrderProcessing, models.OrderShipped, true},
+		{models.OrderPreparing, models.OrderShipped, true},
+		{models.OrderProcessing, models.OrderCancelled, true},
+		{models.OrderPreparing, models.OrderCancelled, true},
+		{models.OrderShipped, models.OrderDelivered, true},
+		{models.OrderProcessing, models.OrderDelivered, false},
+		{models.OrderProcessing, models.OrderProcessing, false},
+		{models.OrderShipped, models.OrderProcessing, false},
+		{models.OrderShipped, models.OrderCancelled, false},
+		{models.OrderDelivered, models.OrderProcessing, false},
+		{models.OrderDelivered, models.OrderCancelled, false},
+		{models.OrderCancelled, models.OrderProcessing, false},
+		{models.OrderCancelled, models.OrderCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			store, buyer := setupStore(t)
+			order := orderWithStatus(t, store, buyer, tt.from)
+			orders := services.NewOrderService(store, clock.Fixed(time.Now()))
+
+			updated, err := orders.UpdateStatus(context.Background(), order.ID, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.to, updated.Status)
+				return
+			}
+			ruleErr, ok := services.AsRuleError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, services.ReasonInvalidTransition, ruleErr.Reason)
+			}
+			stored, err := store.Orders().FindByID(order.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.from, stored.Status)
+		})
+	}
+}
+
+func TestDeliveredOrderKeepsStockAndPayment(t *testing.T) {
+	store, buyer := setupStore(t)
+	order := orderWithStatus(t, store, buyer, models.OrderDelivered)
+	orders := services.NewOrderService(store, clock.Fixed(time.Now()))
+
+	_, err := orders.UpdateStatus(context.Background(), order.ID, models.OrderCancelled)
+	assert.Error(t, err)
+	assert.Equal(t, 3, offerQuantity(t, store, 2))
+	assert.Equal(t, 12.0, store.Balance(buyer.ID))
+}
+
+func TestDeliveringMarksDelivery(t *testing.T) {
+	store, buyer := setupStore(t)
+	order := orderWithStatus(t, store, buyer, models.OrderShipped)
+	now := time.Date(2026, 10, 19, 17, 30, 0, 0, time.UTC)
+
+	_, err := services.NewOrderService(store, clock.Fixed(now)).UpdateStatus(context.Background(), order.ID, models.OrderDelivered)
+	assert.NoError(t, err)
+
+	delivery, err := store.Deliveries().FindByOrder(order.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, delivery.DeliveredAt) {
+		assert.Equal(t, now, *delivery.DeliveredAt)
+	}
+}
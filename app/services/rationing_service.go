@@ -0,0 +1,148 @@
+// app/services/rationing_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+)
+
+// RationingService manages the per-user purchase limits enforced on checkout
+type RationingService struct {
+	Store repositories.Store
+}
+
+func NewRationingService(store repositories.Store) *RationingService {
+	return &RationingService{Store: store}
+}
+
+// Rules lists every rationing rule
+func (s *RationingService) Rules(ctx context.Context) ([]models.RationingRule, error) {
+	return s.Store.WithContext(ctx).Rationing().List()
+}
+
+// Create adds the rule described by request
+func (s *RationingService) Create(ctx context.Context, request models.RationingRuleRequest) (models.RationingRule, error) {
+	store := s.Store.WithContext(ctx)
+	if err := checkRuleOffer(store, request); err != nil {
+		return models.RationingRule{}, err
+	}
+
+	rule := models.RationingRule{}
+	applyRuleRequest(&rule, request)
+	err := store.Rationing().Create(&rule)
+	return rule, err
+}
+
+// Update replaces the target, limit and window of the rule with the given ID
+func (s *RationingService) Update(ctx context.Context, id uint, request models.RationingRuleRequest) (models.RationingRule, error) {
+	store := s.Store.WithContext(ctx)
+	rule, err := store.Rationing().FindByID(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return rule, ruleError(ReasonNotFound, "Rationing rule not found")
+	}
+	if err != nil {
+		return rule, err
+	}
+	if err := checkRuleOffer(store, request); err != nil {
+		return rule, err
+	}
+
+	applyRuleRequest(&rule, request)
+	err = store.Rationing().Update(&rule)
+	return rule, err
+}
+
+// Delete removes the rule with the given ID
+func (s *RationingService) Delete(ctx context.Context, id uint) error {
+	err := s.Store.WithContext(ctx).Rationing().Delete(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ruleError(ReasonNotFound, "Rationing rule not found")
+	}
+	return err
+}
+
+// checkRuleOffer verifies that the offer a rule targets exists
+func checkRuleOffer(store repositories.Store, request models.RationingRuleRequest) error {
+	if request.OfferID == nil {
+		return nil
+	}
+	_, err := store.Offers().FindByID(*request.OfferID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ruleError(ReasonInvalidRequest, "Offer not found")
+	}
+	return err
+}
+
+func applyRuleRequest(rule *models.RationingRule, request models.RationingRuleRequest) {
+	rule.OfferID = request.OfferID
+	rule.Category = request.Category
+	rule.MaxQuantity = request.MaxQuantity
+	rule.WindowHours = request.WindowHours
+	rule.Description = request.Description
+}
+
+// checkRationing verifies that the items about to be ordered by userID respect every rationing rule.
+// It must run inside the checkout transaction so the purchase history it reads is consistent with the order.
+func checkRationing(store repositories.Store, userID uint, items []models.OrderItem, offers map[uint]models.Offer, now time.Time) error {
+	rules, err := store.Rationing().List()
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	for _, rule := range rules {
+		requested := 0
+		for _, item := range items {
+			if ruleMatches(rule, offers[item.OfferID]) {
+				requested += item.Quantity
+			}
+		}
+		if requested == 0 {
+			continue
+		}
+
+		already, err := store.Rationing().Bought(userID, rule, now.Add(-time.Duration(rule.WindowHours)*time.Hour))
+		if err != nil {
+			return err
+		}
+
+		if already+requested > rule.MaxQuantity {
+			violations = append(violations, fmt.Sprintf(
+				"%s: at most %d per %dh per user (already bought %d, requested %d)",
+				ruleTarget(rule, offers), rule.MaxQuantity, rule.WindowHours, already, requested))
+		}
+	}
+
+	if len(violations) > 0 {
+		return ruleError(ReasonRationingLimit, "rationing limit exceeded: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// ruleMatches reports whether rule limits the purchases of offer
+func ruleMatches(rule models.RationingRule, offer models.Offer) bool {
+	if rule.OfferID != nil {
+		return *rule.OfferID == offer.ID
+	}
+	return rule.Category == offer.Category
+}
+
+func ruleTarget(rule models.RationingRule, offers map[uint]models.Offer) string {
+	if rule.Description != "" {
+		return rule.Description
+	}
+	if rule.OfferID != nil {
+		if offer, ok := offers[*rule.OfferID]; ok {
+			return offer.Name
+		}
+		return fmt.Sprintf("offer %d", *rule.OfferID)
+	}
+	return "category " + rule.Category
+}
@@ -0,0 +1,122 @@
+// app/services/reservation_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+)
+
+// ReservationService holds stock for buyers for a while before they pay for it
+type ReservationService struct {
+	Store    repositories.Store
+	Checkout *CheckoutService
+	Clock    clock.Clock
+	Config   config.ReservationsConfig
+}
+
+func NewReservationService(store repositories.Store, checkout *CheckoutService, clk clock.Clock, cfg config.ReservationsConfig) *ReservationService {
+	return &ReservationService{Store: store, Checkout: checkout, Clock: clk, Config: cfg}
+}
+
+// Create holds the requested quantities for buyer until the reservation TTL passes
+func (s *ReservationService) Create(ctx context.Context, buyer models.User, request models.ReservationRequest) (models.Reservation, error) {
+	var reservation models.Reservation
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		now := s.Clock.Now()
+
+		// Lock the offers before reading the holds on them, so concurrent reservations
+		// and checkouts of the same offers see each other's holds
+		offers, err := lockOffers(store, request.Items)
+		if err != nil {
+			return err
+		}
+		reserved, err := store.Reservations().ReservedQuantities(now)
+		if err != nil {
+			return err
+		}
+
+		var items []models.ReservationItem
+		for _, item := range request.Items {
+			if offers[item.OfferID].Quantity-reserved[item.OfferID] < item.Quantity {
+				return ruleError(ReasonInsufficientStock, "Not enough quantity for offer ID %d", item.OfferID)
+			}
+			reserved[item.OfferID] += item.Quantity
+			items = append(items, models.ReservationItem{
+				OfferID:  item.OfferID,
+				Quantity: item.Quantity,
+			})
+		}
+
+		reservation = models.Reservation{
+			UserID:    buyer.ID,
+			Status:    models.ReservationActive,
+			ExpiresAt: now.Add(s.Config.TTL),
+			Items:     items,
+		}
+		return store.Reservations().Create(&reservation)
+	})
+	return reservation, err
+}
+
+// Confirm turns an active reservation of buyer into an order, paid like any other checkout
+func (s *ReservationService) Confirm(ctx context.Context, buyer models.User, id uint) (models.Order, error) {
+	var order models.Order
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		reservation, err := store.Reservations().FindForUpdate(id)
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && reservation.UserID != buyer.ID) {
+			return ruleError(ReasonNotFound, "Reservation not found")
+		}
+		if err != nil {
+			return err
+		}
+		if reservation.Status != models.ReservationActive || !reservation.ExpiresAt.After(s.Clock.Now()) {
+			return ruleError(ReasonConflict, "Reservation is no longer active")
+		}
+
+		// The reservation's own hold is available to the order it turns into
+		request := models.CheckoutRequest{}
+		held := make(map[uint]int, len(reservation.Items))
+		for _, item := range reservation.Items {
+			request.Items = append(request.Items, models.CheckoutItem{OfferID: item.OfferID, Quantity: item.Quantity})
+			held[item.OfferID] += item.Quantity
+		}
+
+		order, err = s.Checkout.placeOrder(store, buyer, request, held)
+		if err != nil {
+			return err
+		}
+
+		reservation.Status = models.ReservationConfirmed
+		reservation.OrderID = &order.ID
+		return store.Reservations().UpdateStatus(&reservation)
+	})
+	return order, err
+}
+
+// Release gives back the stock held by an active reservation of buyerID before it expires
+func (s *ReservationService) Release(ctx context.Context, buyerID, id uint) error {
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		reservation, err := store.Reservations().FindForUpdate(id)
+		if errors.Is(err, repositories.ErrNotFound) ||
+			(err == nil && (reservation.UserID != buyerID || reservation.Status != models.ReservationActive)) {
+			return ruleError(ReasonNotFound, "Active reservation not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		reservation.Status = models.ReservationReleased
+		return store.Reservations().UpdateStatus(&reservation)
+	})
+}
+
+// ExpireStale marks the active reservations whose TTL has passed as expired and counts them
+func (s *ReservationService) ExpireStale(ctx context.Context) (int64, error) {
+	return s.Store.WithContext(ctx).Reservations().Expire(s.Clock.Now())
+}
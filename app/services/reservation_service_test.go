@@ -0,0 +1,249 @@
+// app/services/reservation_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReservationService(store *repositories.MemoryStore, now time.Time) *services.ReservationService {
+	clk := clock.Fixed(now)
+	return services.NewReservationService(store, services.NewCheckoutService(store, clk), clk,
+		config.ReservationsConfig{TTL: 15 * time.Minute})
+}
+
+func available(t *testing.T, store *repositories.MemoryStore, now time.Time, id uint) int {
+	offers, err := services.NewCheckoutService(store, clock.Fixed(now)).AvailableOffers(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list offers: %s", err)
+	}
+	for _, offer := range offers {
+		if offer.ID == id {
+			return offer.Quantity
+		}
+	}
+	t.Fatalf("Offer %d not listed", id)
+	return 0
+}
+
+func TestCreateReservationHoldsStock(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	reservations := newReservationService(store, now)
+
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 2, Quantity: 4},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationActive, reservation.Status)
+	assert.Equal(t, now.Add(15*time.Minute), reservation.ExpiresAt)
+	assert.Equal(t, 1, available(t, store, now, 2))
+	assert.Equal(t, 5, offerQuantity(t, store, 2), "a hold does not take the stock yet")
+
+	// The held stock is not available to other reservations nor to checkouts
+	_, err = reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 2, Quantity: 2},
+	}})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInsufficientStock, ruleErr.Reason)
+	}
+
+	_, err = reservations.Checkout.Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{
+		{OfferID: 2, Quantity: 2},
+	}})
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInsufficientStock, ruleErr.Reason)
+	}
+}
+
+func TestCreateReservationRejectsUnknownOffer(t *testing.T) {
+	store, buyer := setupStore(t)
+	reservations := newReservationService(store, time.Now())
+
+	_, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+		{OfferID: 99, Quantity: 1},
+	}})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonOfferNotFound, ruleErr.Reason)
+	}
+}
+
+func TestConfirmReservationPlacesOrder(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	reservations := newReservationService(store, now)
+
+	// The whole stock of meat is held, which must not keep its own holder from buying it
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 2, Quantity: 5},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	order, err := reservations.Confirm(context.Background(), buyer, reservation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, order.TotalAmount)
+	assert.Equal(t, models.PaymentPaid, order.PaymentStatus)
+	assert.Equal(t, 0.0, store.Balance(buyer.ID))
+	assert.Equal(t, 0, offerQuantity(t, store, 2))
+	assert.Equal(t, 0, available(t, store, now, 2))
+
+	confirmed, err := store.Reservations().FindForUpdate(reservation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationConfirmed, confirmed.Status)
+	if assert.NotNil(t, confirmed.OrderID) {
+		assert.Equal(t, order.ID, *confirmed.OrderID)
+	}
+
+	// A confirmed reservation cannot be confirmed again
+	_, err = reservations.Confirm(context.Background(), buyer, reservation.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+}
+
+func TestConfirmReservationOfAnotherBuyer(t *testing.T) {
+	store, buyer := setupStore(t)
+	reservations := newReservationService(store, time.Now())
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	other := models.User{Username: "other", Email: "other@example.com", Role: services.RoleUser}
+	if err := store.Users().Create(&other); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	_, err = reservations.Confirm(context.Background(), other, reservation.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+	assert.Equal(t, 10, offerQuantity(t, store, 1))
+}
+
+func TestConfirmReservationWithoutCreditsKeepsHold(t *testing.T) {
+	store, buyer := setupStore(t)
+	store.AddOffer(models.Offer{ID: 3, Name: "antibiotics", Quantity: 5, Price: 9, Category: "medicine"})
+	now := time.Now()
+	reservations := newReservationService(store, now)
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 3, Quantity: 3},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = reservations.Confirm(context.Background(), buyer, reservation.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInsufficientCredits, ruleErr.Reason)
+	}
+	assert.Equal(t, 5, offerQuantity(t, store, 3))
+	assert.Equal(t, 2, available(t, store, now, 3))
+	assert.Equal(t, 20.0, store.Balance(buyer.ID))
+}
+
+func TestConfirmExpiredReservation(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	reservations := newReservationService(store, now)
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	reservations.Clock = clock.Fixed(now.Add(15 * time.Minute))
+	_, err = reservations.Confirm(context.Background(), buyer, reservation.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+}
+
+func TestReleaseReservationFreesStock(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	reservations := newReservationService(store, now)
+	reservation, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 6},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 4, available(t, store, now, 1))
+
+	// Only the buyer holding the reservation can release it
+	err = reservations.Release(context.Background(), buyer.ID+1, reservation.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+
+	assert.NoError(t, reservations.Release(context.Background(), buyer.ID, reservation.ID))
+	assert.Equal(t, 10, available(t, store, now, 1))
+
+	// A released reservation is no longer active
+	err = reservations.Release(context.Background(), buyer.ID, reservation.ID)
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+}
+
+func TestExpireStaleReservations(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := time.Now()
+	reservations := newReservationService(store, now)
+	stale, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 2},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	reservations.Clock = clock.Fixed(now.Add(10 * time.Minute))
+	fresh, err := reservations.Create(context.Background(), buyer, models.ReservationRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 3},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	reservations.Clock = clock.Fixed(now.Add(15 * time.Minute))
+	expired, err := reservations.ExpireStale(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	reservation, err := store.Reservations().FindForUpdate(stale.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationExpired, reservation.Status)
+	reservation, err = store.Reservations().FindForUpdate(fresh.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationActive, reservation.Status)
+	assert.Equal(t, 7, available(t, store, now.Add(15*time.Minute), 1))
+
+	// Running the job again finds nothing left to expire
+	expired, err = reservations.ExpireStale(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), expired)
+}
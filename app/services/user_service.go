@@ -0,0 +1,442 @@
+// app/services/user_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/identity"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// UserService registers, finds and removes the users of the market
+type UserService struct {
+	Store     repositories.Store
+	Validator *validation.Validator
+	Clock     clock.Clock
+	Wallet    config.WalletConfig
+}
+
+func NewUserService(store repositories.Store, validator *validation.Validator, clk clock.Clock, wallet config.WalletConfig) *UserService {
+	return &UserService{Store: store, Validator: validator, Clock: clk, Wallet: wallet}
+}
+
+// DefaultUsersPerPage is the page size of the admin user listing when none is asked for
+const DefaultUsersPerPage = 20
+
+// Register creates a buyer account with the starting credits after validating the request, which
+// includes checking that the username and email are free. Invalid requests fail with validation.Errors.
+func (s *UserService) Register(ctx context.Context, request models.RegisterRequest) (models.User, error) {
+	request.Username = identity.Normalize(request.Username)
+	request.Email = identity.Normalize(request.Email)
+	if err := s.Validator.Struct(ctx, request); err != nil {
+		return models.User{}, err
+	}
+
+	hashedPassword, err := utils.BcryptGenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user := models.User{
+		Username: request.Username,
+		Email:    request.Email,
+		Password: string(hashedPassword),
+		Role:     RoleUser,
+	}
+	err = s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		if err := store.Users().Create(&user); err != nil {
+			return err
+		}
+		if s.Wallet.StartingCredits <= 0 {
+			return nil
+		}
+		return store.Wallets().Credit(user.ID, s.Wallet.StartingCredits, models.TransactionGrant, nil, "Starting credits")
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// FindByID returns the user identified by id, usually taken from the JWT or API key of the request
+func (s *UserService) FindByID(ctx context.Context, id uint) (models.User, error) {
+	if id == 0 {
+		return models.User{}, repositories.ErrNotFound
+	}
+	return s.Store.WithContext(ctx).Users().FindByID(id)
+}
+
+// FindByEmail returns the user registered with email
+func (s *UserService) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	if email == "" {
+		return models.User{}, repositories.ErrNotFound
+	}
+	return s.Store.WithContext(ctx).Users().FindByEmail(email)
+}
+
+// dummyHash is compared with the password of logins for unknown emails, so they take as long
+// as logins for registered ones and response times do not reveal which emails have an account
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("not the password of anyone"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// Authenticate returns the user a login request is for once their password matches. Unknown
+// emails and wrong passwords both fail with ReasonInvalidCredentials.
+func (s *UserService) Authenticate(ctx context.Context, request models.LoginRequest) (models.User, error) {
+	invalid := ruleError(ReasonInvalidCredentials, "Invalid credentials")
+	user, err := findByTypedEmail(s.Store.WithContext(ctx).Users(), request.Email)
+	if errors.Is(err, repositories.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(request.Password))
+		return models.User{}, invalid
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		return models.User{}, invalid
+	}
+	return user, nil
+}
+
+// findByTypedEmail finds the user an email typed by someone refers to. Emails are stored normalized,
+// except for the accounts whose normalized email was already taken when they were normalized,
+// which are found first by their email exactly as typed.
+func findByTypedEmail(users repositories.UserRepository, email string) (models.User, error) {
+	if !identity.IsNormalized(email) {
+		user, err := users.FindByEmail(email)
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return user, err
+		}
+	}
+	return users.FindByEmail(identity.Normalize(email))
+}
+
+// Search returns the page of users matching filter, the first page of DefaultUsersPerPage
+// users unless the filter asks for another. Invalid filters fail with validation.Errors.
+func (s *UserService) Search(ctx context.Context, filter models.UserFilter) (models.UserPage, error) {
+	if err := s.Validator.Struct(ctx, filter); err != nil {
+		return models.UserPage{}, err
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PerPage == 0 {
+		filter.PerPage = DefaultUsersPerPage
+	}
+	users, total, err := s.Store.WithContext(ctx).Users().Search(filter)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+	return models.UserPage{Users: users, Page: filter.Page, PerPage: filter.PerPage, Total: total}, nil
+}
+
+// Detail returns the user with the given ID and their order history, even if the user was deleted
+func (s *UserService) Detail(ctx context.Context, id uint) (models.UserDetail, error) {
+	store := s.Store.WithContext(ctx)
+	user, err := store.Users().FindAnyByID(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.UserDetail{}, ruleError(ReasonNotFound, "User not found")
+	}
+	if err != nil {
+		return models.UserDetail{}, err
+	}
+	orders, err := store.Orders().ListByUser(id)
+	if err != nil {
+		return models.UserDetail{}, err
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	summary := user.Summary()
+	summary.OrderCount = int64(len(orders))
+	return models.UserDetail{
+		UserSummary:      summary,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		Shelter:          user.Shelter,
+		Sector:           user.Sector,
+		DeliveryLocation: user.DeliveryLocation,
+		Orders:           orders,
+	}, nil
+}
+
+// Suspend stops the user with the given ID from logging in or using their tokens until
+// unsuspended. Admins cannot suspend themselves.
+func (s *UserService) Suspend(ctx context.Context, admin models.User, id uint) (models.User, error) {
+	if admin.ID == id {
+		return models.User{}, ruleError(ReasonForbidden, "You cannot suspend your own account")
+	}
+	now := s.Clock.Now()
+	return s.setSuspended(ctx, id, &now)
+}
+
+// Unsuspend lifts the suspension of the user with the given ID
+func (s *UserService) Unsuspend(ctx context.Context, id uint) (models.User, error) {
+	return s.setSuspended(ctx, id, nil)
+}
+
+func (s *UserService) setSuspended(ctx context.Context, id uint, at *time.Time) (models.User, error) {
+	users := s.Store.WithContext(ctx).Users()
+	user, err := users.FindByID(id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return models.User{}, ruleError(ReasonNotFound, "User not found")
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	if (user.SuspendedAt != nil) == (at != nil) {
+		// Suspending twice keeps the first date
+		return user, nil
+	}
+	if err := users.SetSuspended(id, at); err != nil {
+		return models.User{}, err
+	}
+	user.SuspendedAt = at
+	return user, nil
+}
+
+// UpdateProfile applies the fields set in request to user. A new email is not verified,
+// the caller mails it a verification token. Invalid requests fail with validation.Errors.
+func (s *UserService) UpdateProfile(ctx context.Context, user models.User, request models.UpdateProfileRequest) (models.User, error) {
+	if request.Username != nil {
+		username := identity.Normalize(*request.Username)
+		request.Username = &username
+	}
+	if request.Email != nil {
+		email := identity.Normalize(*request.Email)
+		request.Email = &email
+	}
+	// Sending the current username or email back is not a change and must not fail as already taken
+	if request.Username != nil && *request.Username == user.Username {
+		request.Username = nil
+	}
+	if request.Email != nil && *request.Email == user.Email {
+		request.Email = nil
+	}
+	if err := s.Validator.Struct(ctx, request); err != nil {
+		return models.User{}, err
+	}
+
+	if request.Username != nil {
+		user.Username = *request.Username
+	}
+	if request.Email != nil {
+		user.Email = *request.Email
+		user.EmailVerifiedAt = nil
+	}
+	if request.Shelter != nil {
+		user.Shelter = *request.Shelter
+	}
+	if request.Sector != nil {
+		user.Sector = *request.Sector
+	}
+	if request.DeliveryLocation != nil {
+		user.DeliveryLocation = *request.DeliveryLocation
+	}
+	if err := s.Store.WithContext(ctx).Users().UpdateProfile(&user); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// DeleteSelf deletes the account of user once they confirm their password. Their personal data
+// is anonymized, while their orders stay in the trade log.
+func (s *UserService) DeleteSelf(ctx context.Context, user models.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ruleError(ReasonForbidden, "password is incorrect")
+	}
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		return anonymize(store, &user)
+	})
+}
+
+// Collisions lists the users whose username or email is the same as another user's once normalized.
+// Migration 0011 left them as typed, they still log in with their email as typed until an admin
+// deletes the duplicates or their owners pick another username or email.
+func (s *UserService) Collisions(ctx context.Context) ([]models.IdentityCollision, error) {
+	users := s.Store.WithContext(ctx).Users()
+	leftovers, err := users.Unnormalized()
+	if err != nil {
+		return nil, err
+	}
+
+	collisions := []models.IdentityCollision{}
+	index := map[[2]string]int{}
+	add := func(field, value string, user models.User, holder func(string) (models.User, error)) error {
+		normalized := identity.Normalize(value)
+		if normalized == value {
+			return nil
+		}
+		i, ok := index[[2]string{field, normalized}]
+		if !ok {
+			collision := models.IdentityCollision{Field: field, Normalized: normalized}
+			found, err := holder(normalized)
+			if err == nil {
+				collision.Users = append(collision.Users, found.Summary())
+			} else if !errors.Is(err, repositories.ErrNotFound) {
+				return err
+			}
+			i = len(collisions)
+			index[[2]string{field, normalized}] = i
+			collisions = append(collisions, collision)
+		}
+		collisions[i].Users = append(collisions[i].Users, user.Summary())
+		return nil
+	}
+	for _, user := range leftovers {
+		if err := add("username", user.Username, user, users.FindByUsername); err != nil {
+			return nil, err
+		}
+		if err := add("email", user.Email, user, users.FindByEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	// A user left alone once the others were deleted collides with nobody anymore
+	reported := collisions[:0]
+	for _, collision := range collisions {
+		if len(collision.Users) > 1 {
+			reported = append(reported, collision)
+		}
+	}
+	return reported, nil
+}
+
+// DeleteMany removes every user in ids, or none if any of them does not exist
+func (s *UserService) DeleteMany(ctx context.Context, ids []uint) error {
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var missing []string
+		users := make([]models.User, 0, len(ids))
+		for _, id := range ids {
+			user, err := store.Users().FindByID(id)
+			if errors.Is(err, repositories.ErrNotFound) {
+				missing = append(missing, strconv.FormatUint(uint64(id), 10))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+		if len(missing) > 0 {
+			return ruleError(ReasonNotFound, "Users not found: %s", strings.Join(missing, ", "))
+		}
+		now := s.Clock.Now()
+		for i := range users {
+			if err := remove(store, &users[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Restore undoes the deletion of the user with the given ID, unless their data was anonymized
+// or someone registered their username or email since
+func (s *UserService) Restore(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		var err error
+		user, err = store.Users().FindAnyByID(id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "User not found")
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case !user.DeletedAt.Valid:
+			return ruleError(ReasonConflict, "User is not deleted")
+		case user.IsAnonymized():
+			return ruleError(ReasonConflict, "User data was anonymized and cannot be restored")
+		}
+
+		if _, err := store.Users().FindByUsername(user.Username); err == nil {
+			return ruleError(ReasonConflict, "Username %q has been taken since the user was deleted", user.Username)
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		if _, err := store.Users().FindByEmail(user.Email); err == nil {
+			return ruleError(ReasonConflict, "Email %q has been registered since the user was deleted", user.Email)
+		} else if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+
+		user.DeletedAt = gorm.DeletedAt{}
+		return store.Users().Restore(id)
+	})
+	return user, err
+}
+
+// Purge permanently wipes the personal data of the user with the given ID, deleting them
+// if they were not already. Their orders stay in the trade log. Admins cannot purge themselves.
+func (s *UserService) Purge(ctx context.Context, admin models.User, id uint) error {
+	if admin.ID == id {
+		return ruleError(ReasonForbidden, "You cannot purge your own account")
+	}
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		user, err := store.Users().FindAnyByID(id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "User not found")
+		}
+		if err != nil {
+			return err
+		}
+		return anonymize(store, &user)
+	})
+}
+
+// Delete removes the user with the given ID
+func (s *UserService) Delete(ctx context.Context, id uint) error {
+	return s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		user, err := store.Users().FindByID(id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "User not found")
+		}
+		if err != nil {
+			return err
+		}
+		return remove(store, &user, s.Clock.Now())
+	})
+}
+
+// remove soft-deletes user and revokes their API keys and JWTs, which stay revoked if the
+// user is restored
+func remove(store repositories.Store, user *models.User, now time.Time) error {
+	if err := store.APIKeys().RevokeAll(user.ID, now); err != nil {
+		return err
+	}
+	if err := store.Users().IncrementTokenVersion(user.ID); err != nil {
+		return err
+	}
+	return store.Users().Delete(user)
+}
+
+// anonymize wipes the personal data of user, API keys included, and revokes their JWTs
+func anonymize(store repositories.Store, user *models.User) error {
+	if err := store.Users().IncrementTokenVersion(user.ID); err != nil {
+		return err
+	}
+	return store.Users().Anonymize(user)
+}
@@ -0,0 +1,360 @@
+// app/services/user_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupUsers(t *testing.T) (*services.UserService, *repositories.MemoryStore, models.User) {
+	store := repositories.NewMemoryStore()
+	hash, err := bcrypt.GenerateFromPassword([]byte("Old-Passw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %s", err)
+	}
+	verified := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, user := range []models.User{
+		{Username: "mia", Email: "mia@example.com", Password: string(hash), Role: services.RoleUser, EmailVerifiedAt: &verified},
+		{Username: "leo", Email: "leo@example.com", Password: string(hash), Role: services.RoleUser},
+	} {
+		if err := store.Users().Create(&user); err != nil {
+			t.Fatalf("Failed to create user: %s", err)
+		}
+	}
+	user, _ := store.Users().FindByUsername("mia")
+	return services.NewUserService(store, validation.New(store, config.Default().Password), clock.Fixed(accountNow), config.Default().Wallet), store, user
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+func TestUpdateProfile(t *testing.T) {
+	users, store, user := setupUsers(t)
+
+	// The current username is not reported as taken
+	updated, err := users.UpdateProfile(context.Background(), user, models.UpdateProfileRequest{
+		Username: ptr("mia"),
+		Shelter:  ptr("Vault 7"),
+		Sector:   ptr("North"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Vault 7", updated.Shelter)
+	assert.NotNil(t, updated.EmailVerifiedAt)
+
+	_, err = users.UpdateProfile(context.Background(), updated, models.UpdateProfileRequest{Username: ptr("leo"), Email: ptr("")})
+	fieldErrs, ok := validation.AsErrors(err)
+	if assert.True(t, ok) && assert.Len(t, fieldErrs, 2) {
+		assert.Equal(t, models.FieldError{Field: "username", Rule: validation.RuleUniqueUsername, Message: "is already taken"}, fieldErrs[0])
+		assert.Equal(t, models.FieldError{Field: "email", Rule: "email", Message: "must be a valid email address"}, fieldErrs[1])
+	}
+	_, err = users.UpdateProfile(context.Background(), updated, models.UpdateProfileRequest{Username: ptr("")})
+	fieldErrs, ok = validation.AsErrors(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "min", fieldErrs[0].Rule)
+	}
+
+	// A new email has to be verified again, display info left out is kept
+	_, err = users.UpdateProfile(context.Background(), updated, models.UpdateProfileRequest{Email: ptr("mia@vault7.org")})
+	assert.NoError(t, err)
+	stored, _ := store.Users().FindByID(user.ID)
+	assert.Equal(t, "mia@vault7.org", stored.Email)
+	assert.Nil(t, stored.EmailVerifiedAt)
+	assert.Equal(t, "North", stored.Sector)
+}
+
+func TestDeleteSelfAnonymizesButKeepsOrders(t *testing.T) {
+	users, store, user := setupUsers(t)
+	order := models.Order{UserID: user.ID, Status: models.OrderDelivered, TotalAmount: 12}
+	if err := store.Orders().Create(&order); err != nil {
+		t.Fatalf("Failed to create order: %s", err)
+	}
+
+	err := users.DeleteSelf(context.Background(), user, "wrong")
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+	}
+
+	assert.NoError(t, users.DeleteSelf(context.Background(), user, "Old-Passw0rd!"))
+	_, err = store.Users().FindByEmail("mia@example.com")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	kept, err := store.Orders().FindByID(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, kept.UserID)
+}
+
+func TestSearchUsersPagesAndFilters(t *testing.T) {
+	users, store, user := setupUsers(t)
+	for _, order := range []models.Order{{UserID: user.ID}, {UserID: user.ID}} {
+		if err := store.Orders().Create(&order); err != nil {
+			t.Fatalf("Failed to create order: %s", err)
+		}
+	}
+
+	page, err := users.Search(context.Background(), models.UserFilter{Query: "EXAMPLE.com", PerPage: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, 1, page.Page)
+	if assert.Len(t, page.Users, 1) {
+		assert.Equal(t, "mia", page.Users[0].Username)
+		assert.Equal(t, int64(2), page.Users[0].OrderCount)
+	}
+
+	page, err = users.Search(context.Background(), models.UserFilter{Query: "leo"})
+	assert.NoError(t, err)
+	assert.Equal(t, services.DefaultUsersPerPage, page.PerPage)
+	assert.Len(t, page.Users, 1)
+
+	_, err = users.Search(context.Background(), models.UserFilter{Role: "courier", PerPage: 500})
+	fieldErrs, ok := validation.AsErrors(err)
+	if assert.True(t, ok) {
+		assert.Len(t, fieldErrs, 2)
+	}
+}
+
+func TestSuspendUser(t *testing.T) {
+	users, _, admin := setupUsers(t)
+	leo, _ := users.FindByEmail(context.Background(), "leo@example.com")
+
+	_, err := users.Suspend(context.Background(), admin, admin.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+	}
+
+	suspended, err := users.Suspend(context.Background(), admin, leo.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, suspended.SuspendedAt) {
+		assert.Equal(t, accountNow, *suspended.SuspendedAt)
+	}
+	leo, err = users.FindByID(context.Background(), leo.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, leo.SuspendedAt)
+
+	page, _ := users.Search(context.Background(), models.UserFilter{Status: models.UserStatusSuspended})
+	assert.Equal(t, int64(1), page.Total)
+
+	_, err = users.Unsuspend(context.Background(), leo.ID)
+	assert.NoError(t, err)
+	leo, _ = users.FindByID(context.Background(), leo.ID)
+	assert.Nil(t, leo.SuspendedAt)
+}
+
+func TestDeleteManyIsAllOrNothing(t *testing.T) {
+	users, store, user := setupUsers(t)
+	leo, _ := users.FindByEmail(context.Background(), "leo@example.com")
+
+	err := users.DeleteMany(context.Background(), []uint{user.ID, 404})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+		assert.Equal(t, "Users not found: 404", ruleErr.Message)
+	}
+	_, err = store.Users().FindByID(user.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, users.DeleteMany(context.Background(), []uint{user.ID, leo.ID}))
+	page, _ := users.Search(context.Background(), models.UserFilter{})
+	assert.Zero(t, page.Total)
+}
+
+func TestRestoreDeletedUser(t *testing.T) {
+	users, store, user := setupUsers(t)
+
+	_, err := users.Restore(context.Background(), user.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+
+	assert.NoError(t, users.Delete(context.Background(), user.ID))
+	page, _ := users.Search(context.Background(), models.UserFilter{Status: models.UserStatusDeleted})
+	assert.Equal(t, int64(1), page.Total)
+	detail, err := users.Detail(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, detail.DeletedAt)
+
+	restored, err := users.Restore(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	stored, err := store.Users().FindByEmail("mia@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, stored.ID)
+}
+
+func TestDeletedUsernameCanBeRegisteredAgain(t *testing.T) {
+	users, store, user := setupUsers(t)
+	assert.NoError(t, users.Delete(context.Background(), user.ID))
+
+	newcomer := models.User{Username: "mia", Email: "mia@vault7.org", Role: services.RoleUser}
+	assert.NoError(t, store.Users().Create(&newcomer))
+
+	_, err := users.Restore(context.Background(), user.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+		assert.Contains(t, ruleErr.Message, "Username")
+	}
+}
+
+func TestPurgeUser(t *testing.T) {
+	users, store, admin := setupUsers(t)
+	leo, _ := users.FindByEmail(context.Background(), "leo@example.com")
+
+	err := users.Purge(context.Background(), admin, admin.ID)
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonForbidden, ruleErr.Reason)
+	}
+
+	assert.NoError(t, users.Delete(context.Background(), leo.ID))
+	assert.NoError(t, users.Purge(context.Background(), admin, leo.ID))
+	purged, err := store.Users().FindAnyByID(leo.ID)
+	assert.NoError(t, err)
+	assert.True(t, purged.IsAnonymized())
+	assert.True(t, purged.DeletedAt.Valid)
+
+	_, err = users.Restore(context.Background(), leo.ID)
+	ruleErr, ok = services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonConflict, ruleErr.Reason)
+	}
+}
+
+func TestRegisterNormalizesIdentities(t *testing.T) {
+	users, store, _ := setupUsers(t)
+
+	user, err := users.Register(context.Background(), models.RegisterRequest{
+		Username: " Ｊｏｅｌ ",
+		Email:    "Joel@Shelter.ORG",
+		Password: "Gr33n-Valley!",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "joel", user.Username)
+	assert.Equal(t, "joel@shelter.org", user.Email)
+	_, err = store.Users().FindByEmail("joel@shelter.org")
+	assert.NoError(t, err)
+
+	_, err = users.Register(context.Background(), models.RegisterRequest{
+		Username: "JOEL",
+		Email:    "joel@shelter.org ",
+		Password: "Gr33n-Valley!",
+	})
+	fieldErrs, ok := validation.AsErrors(err)
+	if assert.True(t, ok) && assert.Len(t, fieldErrs, 2) {
+		assert.Equal(t, validation.RuleUniqueUsername, fieldErrs[0].Rule)
+		assert.Equal(t, validation.RuleUniqueEmail, fieldErrs[1].Rule)
+	}
+
+	// Sending back the current email in another case is not a change
+	updated, err := users.UpdateProfile(context.Background(), user, models.UpdateProfileRequest{Email: ptr("JOEL@shelter.org")})
+	assert.NoError(t, err)
+	assert.Equal(t, "joel@shelter.org", updated.Email)
+}
+
+func TestCollisionsListUsersLeftAsTyped(t *testing.T) {
+	users, store, mia := setupUsers(t)
+	for _, user := range []models.User{
+		{Username: "Mia", Email: "mia@vault7.org", Role: services.RoleUser},
+		{Username: "abby", Email: "Abby@Example.com", Role: services.RoleUser},
+		{Username: "abby2", Email: "ABBY@example.com", Role: services.RoleUser},
+	} {
+		if err := store.Users().Create(&user); err != nil {
+			t.Fatalf("Failed to create user: %s", err)
+		}
+	}
+
+	collisions, err := users.Collisions(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, collisions, 2) {
+		assert.Equal(t, "username", collisions[0].Field)
+		assert.Equal(t, "mia", collisions[0].Normalized)
+		if assert.Len(t, collisions[0].Users, 2) {
+			assert.Equal(t, mia.ID, collisions[0].Users[0].ID)
+			assert.Equal(t, "Mia", collisions[0].Users[1].Username)
+		}
+
+		// Nobody holds the normalized email, both users kept theirs as typed
+		assert.Equal(t, "email", collisions[1].Field)
+		assert.Equal(t, "abby@example.com", collisions[1].Normalized)
+		assert.Len(t, collisions[1].Users, 2)
+	}
+
+	assert.NoError(t, users.Delete(context.Background(), mia.ID))
+	collisions, err = users.Collisions(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, collisions, 1) {
+		assert.Equal(t, "email", collisions[0].Field)
+	}
+}
+
+func TestAuthenticateFindsUsersLeftAsTyped(t *testing.T) {
+	users, store, mia := setupUsers(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("Typed-Passw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %s", err)
+	}
+	typed := models.User{Username: "Mia", Email: "Mia@Example.com", Password: string(hash), Role: services.RoleUser}
+	if err := store.Users().Create(&typed); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+
+	// The account left as typed is found first, the others log in with any casing
+	user, err := users.Authenticate(context.Background(), models.LoginRequest{Email: "Mia@Example.com", Password: "Typed-Passw0rd!"})
+	assert.NoError(t, err)
+	assert.Equal(t, typed.ID, user.ID)
+
+	user, err = users.Authenticate(context.Background(), models.LoginRequest{Email: "MIA@example.com", Password: "Old-Passw0rd!"})
+	assert.NoError(t, err)
+	assert.Equal(t, mia.ID, user.ID)
+
+	for _, request := range []models.LoginRequest{
+		{Email: "Mia@Example.com", Password: "Old-Passw0rd!"},
+		{Email: "nobody@example.com", Password: "Old-Passw0rd!"},
+	} {
+		_, err := users.Authenticate(context.Background(), request)
+		ruleErr, ok := services.AsRuleError(err)
+		if assert.True(t, ok, request.Email) {
+			assert.Equal(t, services.ReasonInvalidCredentials, ruleErr.Reason)
+		}
+	}
+}
+
+func TestDeletingUsersRevokesTheirCredentials(t *testing.T) {
+	users, store, mia := setupUsers(t)
+	leo, _ := users.FindByEmail(context.Background(), "leo@example.com")
+	for _, user := range []models.User{mia, leo} {
+		key := models.APIKey{UserID: user.ID, Name: "sync", Prefix: "nwk_" + user.Username, Scopes: models.ScopeRead}
+		if err := store.APIKeys().Create(&key); err != nil {
+			t.Fatalf("Failed to create API key: %s", err)
+		}
+	}
+
+	// Keys and JWTs stay revoked once the user is restored
+	assert.NoError(t, users.Delete(context.Background(), mia.ID))
+	restored, err := users.Restore(context.Background(), mia.ID)
+	assert.NoError(t, err)
+	restored, _ = users.FindByID(context.Background(), restored.ID)
+	assert.Equal(t, mia.TokenVersion+1, restored.TokenVersion)
+	keys, _ := store.APIKeys().List(mia.ID)
+	if assert.Len(t, keys, 1) {
+		assert.NotNil(t, keys[0].RevokedAt)
+	}
+
+	assert.NoError(t, users.DeleteSelf(context.Background(), leo, "Old-Passw0rd!"))
+	deleted, _ := store.Users().FindAnyByID(leo.ID)
+	assert.Equal(t, leo.TokenVersion+1, deleted.TokenVersion)
+	keys, _ = store.APIKeys().List(leo.ID)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,51 @@
+// app/services/wallet_service.go
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+)
+
+// WalletService reads and tops up the credits users pay their orders with
+type WalletService struct {
+	Store repositories.Store
+}
+
+func NewWalletService(store repositories.Store) *WalletService {
+	return &WalletService{Store: store}
+}
+
+// Balance returns the credits of userID
+func (s *WalletService) Balance(ctx context.Context, userID uint) (float64, error) {
+	return s.Store.WithContext(ctx).Wallets().Balance(userID)
+}
+
+// Transactions returns the credit ledger of userID, newest first
+func (s *WalletService) Transactions(ctx context.Context, userID uint) ([]models.WalletTransaction, error) {
+	return s.Store.WithContext(ctx).Wallets().Transactions(userID)
+}
+
+// Grant credits the wallet of userID, e.g. as payment for labour, and returns the new balance
+func (s *WalletService) Grant(ctx context.Context, userID uint, request models.GrantCreditsRequest) (float64, error) {
+	var balance float64
+	err := s.Store.WithContext(ctx).Transaction(func(store repositories.Store) error {
+		_, err := store.Users().FindByID(userID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ruleError(ReasonNotFound, "User not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := store.Wallets().Credit(userID, request.Amount, models.TransactionGrant, nil, request.Description); err != nil {
+			return err
+		}
+		balance, err = store.Wallets().Balance(userID)
+		return err
+	})
+	return balance, err
+}
@@ -0,0 +1,140 @@
+// app/services/wallet_service_test.go
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrantCredits(t *testing.T) {
+	store, buyer := setupStore(t)
+	wallets := services.NewWalletService(store)
+
+	balance, err := wallets.Grant(context.Background(), buyer.ID, models.GrantCreditsRequest{Amount: 15, Description: "Water purification shift"})
+	assert.NoError(t, err)
+	assert.Equal(t, 35.0, balance)
+
+	transactions, err := wallets.Transactions(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 2) {
+		assert.Equal(t, 15.0, transactions[0].Amount)
+		assert.Equal(t, models.TransactionGrant, transactions[0].Type)
+		assert.Equal(t, "Water purification shift", transactions[0].Description)
+	}
+
+	_, err = wallets.Grant(context.Background(), buyer.ID+1, models.GrantCreditsRequest{Amount: 15, Description: "Nobody"})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonNotFound, ruleErr.Reason)
+	}
+}
+
+func TestCheckoutDebitsWallet(t *testing.T) {
+	store, buyer := setupStore(t)
+	wallets := services.NewWalletService(store)
+
+	order, err := services.NewCheckoutService(store, clock.Fixed(time.Now())).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 2, Quantity: 3}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	balance, err := wallets.Balance(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 8.0, balance)
+
+	transactions, err := wallets.Transactions(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 2) {
+		assert.Equal(t, -12.0, transactions[0].Amount)
+		assert.Equal(t, models.TransactionPurchase, transactions[0].Type)
+		if assert.NotNil(t, transactions[0].OrderID) {
+			assert.Equal(t, order.ID, *transactions[0].OrderID)
+		}
+	}
+}
+
+func TestCheckoutRejectsInsufficientBalance(t *testing.T) {
+	store, buyer := setupStore(t)
+	wallets := services.NewWalletService(store)
+
+	// 5 meat cost 20 credits, all the buyer has, so one more water is too much
+	_, err := services.NewCheckoutService(store, clock.Fixed(time.Now())).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{
+			{OfferID: 1, Quantity: 1},
+			{OfferID: 2, Quantity: 5},
+		}})
+	ruleErr, ok := services.AsRuleError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, services.ReasonInsufficientCredits, ruleErr.Reason)
+	}
+
+	balance, err := wallets.Balance(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, balance)
+	transactions, err := wallets.Transactions(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+}
+
+func TestCancelRefundsWallet(t *testing.T) {
+	store, buyer := setupStore(t)
+	now := clock.Fixed(time.Now())
+	wallets := services.NewWalletService(store)
+
+	order, err := services.NewCheckoutService(store, now).
+		Checkout(context.Background(), buyer, models.CheckoutRequest{Items: []models.CheckoutItem{{OfferID: 1, Quantity: 5}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = services.NewOrderService(store, now).UpdateStatus(context.Background(), order.ID, models.OrderCancelled)
+	assert.NoError(t, err)
+
+	balance, err := wallets.Balance(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, balance)
+
+	transactions, err := wallets.Transactions(context.Background(), buyer.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 3) {
+		assert.Equal(t, 5.0, transactions[0].Amount)
+		assert.Equal(t, models.TransactionRefund, transactions[0].Type)
+		if assert.NotNil(t, transactions[0].OrderID) {
+			assert.Equal(t, order.ID, *transactions[0].OrderID)
+		}
+	}
+}
+
+func TestRegisterGrantsStartingCredits(t *testing.T) {
+	users, store, _ := setupUsers(t)
+
+	user, err := users.Register(context.Background(), models.RegisterRequest{
+		Username: "ada",
+		Email:    "ada@example.com",
+		Password: "Sturdy-Passw0rd!",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, users.Wallet.StartingCredits, store.Balance(user.ID))
+
+	// No starting credits leaves new users without a wallet entry
+	users.Wallet.StartingCredits = 0
+	user, err = users.Register(context.Background(), models.RegisterRequest{
+		Username: "bob",
+		Email:    "bob@example.com",
+		Password: "Sturdy-Passw0rd!",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	transactions, err := services.NewWalletService(store).Transactions(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+}
@@ -3,16 +3,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/logger"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/routes"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/telemetry"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
 )
 
 // @title New World API - Operating Systems Lab 3
@@ -22,58 +36,212 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
-	// Load .env file only if we're not in a GitHub Actions environment
-	if os.Getenv("GITHUB_ACTIONS") != "true" {
-		err := godotenv.Load()
+	// `main migrate <up|down|status>` manages the schema instead of starting the server,
+	// and only needs the database settings
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		dbCfg, err := config.LoadDatabase()
 		if err != nil {
-			log.Println("Warning: Error loading .env file:", err)
-			// Don't fatal here, as the environment variables might be set another way
+			fatal("Failed to load database configuration", "error", err)
+		}
+		if err := runMigrate(os.Args[2:], dbCfg.DSN()); err != nil {
+			fatal("Migrate failed", "error", err)
 		}
+		return
 	}
 
-	dbHost := os.Getenv("DB_HOST")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
+	// Load configuration from env, .env and the optional YAML file in CONFIG_FILE
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Failed to load configuration", "error", err)
+	}
+	slog.SetDefault(logger.New(cfg.Log, os.Stdout))
 
-	connStr := fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=disable port=%s",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
+	// Trace requests, queries and outbound calls, continuing the trace context sent by Traefik
+	flushTraces, err := telemetry.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
 
 	// Initialize database connection
-	database.InitDB(connStr)
-	defer database.CloseDB()
-	fmt.Println("Successfully connected to the database!")
+	db := database.InitDB(cfg.Database.DSN())
+	slog.Info("Successfully connected to the database")
+	if err := telemetry.InstrumentDB(db); err != nil {
+		fatal("Failed to trace database queries", "error", err)
+	}
 
-	// Create new Fiber server
-	app := fiber.New()
+	// Build the dependencies shared by controllers and background jobs
+	deps := container.New(cfg, db)
+
+	// Expose the connection pool and stock metrics
+	metrics.RegisterDB(db)
+
+	// Create new Fiber server, answering returned errors with an ErrorResponse. Behind a reverse
+	// proxy the client IP, which anonymous requests are rate limited by, is read from its header,
+	// but only on requests coming from the trusted proxies so clients cannot spoof it.
+	app := fiber.New(fiber.Config{
+		ErrorHandler:            middleware.ErrorHandler,
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+		TrustedProxies:          cfg.Server.TrustedProxies,
+	})
+
+	// Tag every request with an ID, trace it, log it, and count and time it
+	app.Use(middleware.RequestIDMiddleware)
+	app.Use(middleware.NewTracingMiddleware())
+	app.Use(middleware.AccessLogMiddleware)
+	app.Use(middleware.MetricsMiddleware)
 
 	// Middleware for CORS
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:3001, http://localhost:3000", // 3001 for local dev and qa, 3002 for docker deployment
-		AllowMethods: "GET,POST,PUT,DELETE",
-		AllowHeaders: "Content-Type,Authorization",
+		AllowOrigins: strings.Join(cfg.Server.CORSOrigins, ", "),
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE",
+		AllowHeaders: "Content-Type,Authorization," + middleware.APIKeyHeader + "," + middleware.RequestIDHeader,
+		ExposeHeaders: strings.Join([]string{
+			middleware.RequestIDHeader,
+			middleware.RateLimitLimitHeader,
+			middleware.RateLimitRemainingHeader,
+			middleware.RateLimitResetHeader,
+			fiber.HeaderRetryAfter,
+		}, ","),
 	}))
 
 	// First supply fetch from /supplies endpoint of HPCPP lab
-	utils.FetchAndStoreSupplies()
+	// If it fails the server still starts, but reports not ready until a sync succeeds
+	deps.SuppliesSync.Run()
 
 	// Start cron job
-	utils.StartCronJob()
+	scheduler := utils.StartCronJob(cfg.Supplies, cfg.Reservations, deps.SuppliesSync, deps.ReservationService.ExpireStale)
 
 	// Register routes
-	routes.SwaggerRoute(app)     // Register a route for API Docs (Swagger).
-	routes.SetupAuthRoutes(app)  // Register routes for the Auth API.
-	routes.SetupAdminRoutes(app) // Register routes for the Admin API.
-	routes.NotFoundRoute(app)    // Register a route for 404 Not Found.
-
-	// Get the port from the environment variable, default to 3000 if not set
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
+	routes.SwaggerRoute(app)            // Register a route for API Docs (Swagger).
+	routes.SetupHealthRoutes(app, deps) // Register the health, readiness and version probes.
+	routes.SetupAuthRoutes(app, deps)   // Register routes for the Auth API.
+	routes.SetupAdminRoutes(app, deps)  // Register routes for the Admin API.
+	routes.NotFoundRoute(app)           // Register a route for 404 Not Found.
+
+	// Listen on the configured port until the process is asked to stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Metrics are served on their own internal port, out of reach of the public API port
+	metricsApp := routes.NewMetricsApp()
+
+	listenErr := make(chan error, 2)
+	go func() {
+		listenErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+	go func() {
+		listenErr <- metricsApp.Listen(":" + cfg.Server.MetricsPort)
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-listenErr:
+		slog.Error("Server stopped", "error", err)
+		exitCode = 1
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining in-flight requests")
+	}
+	stop()
+
+	if err := shutdown(app, metricsApp, scheduler, db, flushTraces, cfg.Server.ShutdownTimeout); err != nil {
+		slog.Error("Shutdown was not clean", "error", err)
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+// shutdown stops accepting connections, waits for in-flight requests and running cron jobs
+// to finish within timeout, closes the database pool and flushes the pending spans
+func shutdown(app, metricsApp *fiber.App, scheduler *cron.Cron, db *gorm.DB, flushTraces func(context.Context) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain HTTP requests: %w", err))
+	}
+	if err := metricsApp.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to stop the metrics server: %w", err))
+	}
+
+	// Stop scheduling jobs and wait for the ones already running
+	select {
+	case <-scheduler.Stop().Done():
+	case <-ctx.Done():
+		errs = append(errs, errors.New("timed out waiting for running cron jobs"))
 	}
 
-	// Listen on the specified port
-	log.Fatal(app.Listen(":" + port))
+	if err := database.CloseDB(db); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close database connection: %w", err))
+	}
+	if err := flushTraces(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// runMigrate executes the migrate subcommand: up, down [steps] or status. It returns instead of
+// exiting so the deferred close runs before main reports the error.
+func runMigrate(args []string, connStr string) error {
+	if len(args) == 0 {
+		return errors.New("usage: main migrate <up|down [steps]|status>")
+	}
+
+	db, err := database.OpenDB(connStr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	defer database.CloseDB(db)
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		for _, version := range applied {
+			fmt.Printf("Applied migration %d\n", version)
+		}
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := database.MigrateDown(db, steps)
+		for _, version := range reverted {
+			fmt.Printf("Reverted migration %d\n", version)
+		}
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			return fmt.Errorf("failed to read migration status: %w", err)
+		}
+		if err := database.WriteStatus(os.Stdout, states); err != nil {
+			return fmt.Errorf("failed to print migration status: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	return nil
+}
+
+// fatal logs msg with its attributes and exits, for errors that leave nothing to serve
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,129 @@
+// pkg/apierror/apierror.go
+
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Codes let clients tell errors apart without parsing the message
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeTokenExpired     = "token_expired"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeLoginLocked      = "login_locked"
+	CodeRateLimited      = "rate_limited"
+	CodeAccountSuspended = "account_suspended"
+	CodeInternal         = "internal_error"
+)
+
+// Error is returned by handlers and middleware and rendered by the error handler
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details []models.FieldError
+	// Cause is logged by the error handler but never sent to the client
+	Cause error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// New creates an error answered with status, identified by code
+func New(status int, code, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// BadRequest reports a body or parameter the handler could not read
+func BadRequest(message string) *Error {
+	return New(fiber.StatusBadRequest, CodeBadRequest, "%s", message)
+}
+
+// Validation reports a request that was read but broke the rules of some of its fields
+func Validation(message string, details ...models.FieldError) *Error {
+	err := New(fiber.StatusBadRequest, CodeValidationFailed, "%s", message)
+	err.Details = details
+	return err
+}
+
+// Unauthorized reports a missing, invalid or unknown credential
+func Unauthorized(message string) *Error {
+	return New(fiber.StatusUnauthorized, CodeUnauthorized, "%s", message)
+}
+
+// Forbidden reports a caller that is authenticated but not allowed to do what it asked
+func Forbidden(message string) *Error {
+	return New(fiber.StatusForbidden, CodeForbidden, "%s", message)
+}
+
+// NotFound reports a resource that does not exist or is not visible to the caller
+func NotFound(message string) *Error {
+	return New(fiber.StatusNotFound, CodeNotFound, "%s", message)
+}
+
+// Conflict reports a request that clashes with the current state of a resource
+func Conflict(message string) *Error {
+	return New(fiber.StatusConflict, CodeConflict, "%s", message)
+}
+
+// Internal reports a failure of the server, describing what was attempted in message
+// and keeping cause for the logs
+func Internal(message string, cause error) *Error {
+	err := New(fiber.StatusInternalServerError, CodeInternal, "%s", message)
+	err.Cause = cause
+	return err
+}
+
+// From turns any error into an Error: errors created by Fiber keep their status
+// and anything unexpected becomes an internal error
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, CodeForStatus(fiberErr.Code), "%s", fiberErr.Message)
+	}
+	return Internal(fiber.ErrInternalServerError.Message, err)
+}
+
+// CodeForStatus returns the code used for errors that only carry an HTTP status
+func CodeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	}
+	if status >= fiber.StatusInternalServerError {
+		return CodeInternal
+	}
+	// e.g. 429 Too Many Requests becomes too_many_requests
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
@@ -0,0 +1,26 @@
+// pkg/clock/clock.go
+
+package clock
+
+import "time"
+
+// Clock tells the current time, so time-dependent rules can be tested with a fixed moment
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the wall clock
+type Real struct{}
+
+// Now returns the current local time
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed is a clock stopped at a given moment
+type Fixed time.Time
+
+// Now returns the moment the clock is stopped at
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
@@ -0,0 +1,642 @@
+// pkg/config/config.go
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the API, loaded once at startup
+type Config struct {
+	Server       ServerConfig       `yaml:"server"`
+	Database     DatabaseConfig     `yaml:"database"`
+	JWT          JWTConfig          `yaml:"jwt"`
+	Supplies     SuppliesConfig     `yaml:"supplies"`
+	Reservations ReservationsConfig `yaml:"reservations"`
+	Wallet       WalletConfig       `yaml:"wallet"`
+	Log          LogConfig          `yaml:"log"`
+	Tracing      TracingConfig      `yaml:"tracing"`
+	Password     PasswordConfig     `yaml:"password"`
+	Tokens       TokensConfig       `yaml:"tokens"`
+	Mail         MailConfig         `yaml:"mail"`
+	Lockout      LockoutConfig      `yaml:"lockout"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	MetricsPort     string        `yaml:"metrics_port"` // Internal port serving /metrics, kept off the public port
+	CORSOrigins     []string      `yaml:"cors_origins"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // How long in-flight requests and jobs may take to finish on shutdown
+	ProxyHeader     string        `yaml:"proxy_header"`     // Header with the client IP set by the reverse proxy, e.g. X-Forwarded-For
+	TrustedProxies  []string      `yaml:"trusted_proxies"`  // IPs or CIDRs allowed to set ProxyHeader, required with it
+}
+
+// DatabaseConfig holds the Postgres connection settings
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	Port     string `yaml:"port"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+// JWTConfig holds the settings used to sign and verify tokens
+type JWTConfig struct {
+	SecretKey string        `yaml:"secret_key"`
+	TTL       time.Duration `yaml:"ttl"`
+}
+
+// SuppliesConfig holds the settings of the HPCPP supplies client
+type SuppliesConfig struct {
+	URL      string        `yaml:"url"`
+	Schedule string        `yaml:"schedule"`
+	Timeout  time.Duration `yaml:"timeout"`
+	MaxAge   time.Duration `yaml:"max_age"` // The API is not ready when the last successful sync is older than this
+}
+
+// ReservationsConfig holds the settings of stock reservations
+type ReservationsConfig struct {
+	TTL            time.Duration `yaml:"ttl"`
+	ExpirySchedule string        `yaml:"expiry_schedule"` // Cron schedule of the job releasing expired reservations
+}
+
+// WalletConfig holds the settings of the credits users pay their orders with
+type WalletConfig struct {
+	StartingCredits float64 `yaml:"starting_credits"` // Credits granted to every user when they register
+}
+
+// LogConfig holds the settings of the structured logger
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error
+	Format string `yaml:"format"` // json or text
+}
+
+// TracingConfig holds the settings of the OpenTelemetry trace exporter
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`     // none, otlp or stdout
+	Endpoint    string  `yaml:"endpoint"`     // host:port of the OTLP/HTTP collector
+	Insecure    bool    `yaml:"insecure"`     // Send to the collector over plain HTTP
+	SampleRatio float64 `yaml:"sample_ratio"` // Fraction of the traces started here that are recorded
+}
+
+// PasswordConfig holds the password policy applied on registration and password changes
+type PasswordConfig struct {
+	MinLength   int  `yaml:"min_length"`
+	MinClasses  int  `yaml:"min_classes"`  // How many of lowercase, uppercase, digits and symbols a password must mix
+	CheckCommon bool `yaml:"check_common"` // Reject the passwords in the bundled list of common passwords
+}
+
+// TokensConfig holds how long the single-use tokens mailed to users stay valid
+type TokensConfig struct {
+	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+}
+
+// MailConfig holds the settings of the mailer that delivers password resets and email verifications
+type MailConfig struct {
+	Driver      string `yaml:"driver"`        // log or file
+	Path        string `yaml:"path"`          // File the file driver appends messages to
+	From        string `yaml:"from"`          // Sender of every message
+	LinkBaseURL string `yaml:"link_base_url"` // Frontend URL the links in the messages point to
+}
+
+// LockoutConfig holds how failed logins lock an account or a client IP out
+type LockoutConfig struct {
+	MaxAccountFailures int           `yaml:"max_account_failures"` // Failed logins for one email before it is locked
+	MaxIPFailures      int           `yaml:"max_ip_failures"`      // Failed logins from one IP before it is locked
+	BaseDuration       time.Duration `yaml:"base_duration"`        // First lockout, doubled by every lockout that follows
+	MaxDuration        time.Duration `yaml:"max_duration"`         // Longest lockout
+	Window             time.Duration `yaml:"window"`               // Failures and lockouts older than this are forgotten
+}
+
+// RateLimitConfig holds the request limits of each group of routes
+type RateLimitConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Anonymous RateLimitRule `yaml:"anonymous"` // Unauthenticated auth routes such as login, per client IP
+	Buyer     RateLimitRule `yaml:"buyer"`     // Authenticated auth routes, per user
+	Admin     RateLimitRule `yaml:"admin"`     // Admin routes, per admin
+}
+
+// RateLimitRule allows Requests requests in every Window
+type RateLimitRule struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// ParseRateLimitRule reads a rule written as requests/window, e.g. 60/1m
+func ParseRateLimitRule(value string) (RateLimitRule, error) {
+	requests, window, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("%q is not written as requests/window", value)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil {
+		return RateLimitRule{}, fmt.Errorf("%q does not start with a number of requests", value)
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil {
+		return RateLimitRule{}, fmt.Errorf("%q does not end with a duration", value)
+	}
+	return RateLimitRule{Requests: count, Window: duration}, nil
+}
+
+func (r RateLimitRule) String() string {
+	return fmt.Sprintf("%d/%s", r.Requests, r.Window)
+}
+
+// MaxPasswordBytes is the longest password bcrypt can hash, it ignores anything past it
+const MaxPasswordBytes = 72
+
+// DSN returns the Postgres connection string
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=%s port=%s",
+		d.Host, d.User, d.Password, d.Name, d.SSLMode, d.Port)
+}
+
+// Default returns the configuration used when nothing overrides a setting
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            "3000",
+			MetricsPort:     "9090",
+			CORSOrigins:     []string{"http://localhost:3001", "http://localhost:3000"}, // 3001 for local dev and qa, 3002 for docker deployment
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Port:    "5432",
+			SSLMode: "disable",
+		},
+		JWT: JWTConfig{
+			TTL: 24 * time.Hour,
+		},
+		Supplies: SuppliesConfig{
+			URL:      "http://192.168.0.57:8011/supplies?id=latest",
+			Schedule: "@hourly",
+			Timeout:  10 * time.Second,
+			MaxAge:   2 * time.Hour,
+		},
+		Reservations: ReservationsConfig{
+			TTL:            15 * time.Minute,
+			ExpirySchedule: "@every 1m",
+		},
+		Wallet: WalletConfig{
+			StartingCredits: 100,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			SampleRatio: 1,
+		},
+		Password: PasswordConfig{
+			MinLength:   10,
+			MinClasses:  3,
+			CheckCommon: true,
+		},
+		Tokens: TokensConfig{
+			PasswordResetTTL:     time.Hour,
+			EmailVerificationTTL: 48 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   true,
+			Anonymous: RateLimitRule{Requests: 20, Window: time.Minute},
+			Buyer:     RateLimitRule{Requests: 120, Window: time.Minute},
+			Admin:     RateLimitRule{Requests: 300, Window: time.Minute},
+		},
+		Lockout: LockoutConfig{
+			MaxAccountFailures: 5,
+			MaxIPFailures:      20,
+			BaseDuration:       time.Minute,
+			MaxDuration:        time.Hour,
+			Window:             time.Hour,
+		},
+		Mail: MailConfig{
+			Driver:      "log",
+			Path:        "mail.log",
+			From:        "no-reply@newworld.local",
+			LinkBaseURL: "http://localhost:3001",
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the optional YAML file in CONFIG_FILE,
+// the .env file and the environment, in increasing order of precedence, and validates it
+func Load() (*Config, error) {
+	cfg, err := read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadDatabase reads the configuration from the same sources as Load but only validates the
+// database settings, so the migrate subcommand runs without the server's secrets
+func LoadDatabase() (DatabaseConfig, error) {
+	cfg, err := read()
+	if err != nil {
+		return DatabaseConfig{}, err
+	}
+	if errs := cfg.Database.validate(); len(errs) > 0 {
+		return DatabaseConfig{}, invalid(errs)
+	}
+	return cfg.Database, nil
+}
+
+// read builds the configuration from defaults, CONFIG_FILE, .env and the environment without validating it
+func read() (Config, error) {
+	// Load .env file only if we're not in a GitHub Actions environment
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		if err := godotenv.Load(); err != nil {
+			// Don't fail here, as the environment variables might be set another way
+			slog.Warn("Error loading .env file", "error", err)
+		}
+	}
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	err := applyEnv(&cfg, os.LookupEnv)
+	return cfg, err
+}
+
+// applyEnv overrides cfg with the environment variables that are set
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	stringVars := []struct {
+		name   string
+		target *string
+	}{
+		{"PORT", &cfg.Server.Port},
+		{"METRICS_PORT", &cfg.Server.MetricsPort},
+		{"PROXY_HEADER", &cfg.Server.ProxyHeader},
+		{"DB_HOST", &cfg.Database.Host},
+		{"DB_USER", &cfg.Database.User},
+		{"DB_PASSWORD", &cfg.Database.Password},
+		{"DB_NAME", &cfg.Database.Name},
+		{"DB_PORT", &cfg.Database.Port},
+		{"DB_SSLMODE", &cfg.Database.SSLMode},
+		{"JWT_SECRET_KEY", &cfg.JWT.SecretKey},
+		{"SUPPLIES_URL", &cfg.Supplies.URL},
+		{"SUPPLIES_SCHEDULE", &cfg.Supplies.Schedule},
+		{"RESERVATION_EXPIRY_SCHEDULE", &cfg.Reservations.ExpirySchedule},
+		{"LOG_LEVEL", &cfg.Log.Level},
+		{"LOG_FORMAT", &cfg.Log.Format},
+		{"TRACING_EXPORTER", &cfg.Tracing.Exporter},
+		{"TRACING_ENDPOINT", &cfg.Tracing.Endpoint},
+		{"MAIL_DRIVER", &cfg.Mail.Driver},
+		{"MAIL_PATH", &cfg.Mail.Path},
+		{"MAIL_FROM", &cfg.Mail.From},
+		{"MAIL_LINK_BASE_URL", &cfg.Mail.LinkBaseURL},
+	}
+	for _, v := range stringVars {
+		if value, ok := lookup(v.name); ok && value != "" {
+			*v.target = value
+		}
+	}
+
+	durationVars := []struct {
+		name   string
+		target *time.Duration
+	}{
+		{"SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout},
+		{"JWT_TTL", &cfg.JWT.TTL},
+		{"SUPPLIES_TIMEOUT", &cfg.Supplies.Timeout},
+		{"SUPPLIES_MAX_AGE", &cfg.Supplies.MaxAge},
+		{"RESERVATION_TTL", &cfg.Reservations.TTL},
+		{"PASSWORD_RESET_TTL", &cfg.Tokens.PasswordResetTTL},
+		{"EMAIL_VERIFICATION_TTL", &cfg.Tokens.EmailVerificationTTL},
+		{"LOCKOUT_BASE_DURATION", &cfg.Lockout.BaseDuration},
+		{"LOCKOUT_MAX_DURATION", &cfg.Lockout.MaxDuration},
+		{"LOCKOUT_WINDOW", &cfg.Lockout.Window},
+	}
+	var errs []string
+	for _, v := range durationVars {
+		value, ok := lookup(v.name)
+		if !ok || value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be a duration such as 15m or 24h, got %q", v.name, value))
+			continue
+		}
+		*v.target = duration
+	}
+
+	intVars := []struct {
+		name   string
+		target *int
+	}{
+		{"PASSWORD_MIN_LENGTH", &cfg.Password.MinLength},
+		{"PASSWORD_MIN_CLASSES", &cfg.Password.MinClasses},
+		{"LOCKOUT_MAX_ACCOUNT_FAILURES", &cfg.Lockout.MaxAccountFailures},
+		{"LOCKOUT_MAX_IP_FAILURES", &cfg.Lockout.MaxIPFailures},
+	}
+	for _, v := range intVars {
+		value, ok := lookup(v.name)
+		if !ok || value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be a whole number, got %q", v.name, value))
+			continue
+		}
+		*v.target = number
+	}
+
+	boolVars := []struct {
+		name   string
+		target *bool
+	}{
+		{"TRACING_INSECURE", &cfg.Tracing.Insecure},
+		{"PASSWORD_CHECK_COMMON", &cfg.Password.CheckCommon},
+		{"RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled},
+	}
+	for _, v := range boolVars {
+		value, ok := lookup(v.name)
+		if !ok || value == "" {
+			continue
+		}
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be true or false, got %q", v.name, value))
+			continue
+		}
+		*v.target = flag
+	}
+
+	if value, ok := lookup("TRACING_SAMPLE_RATIO"); ok && value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("TRACING_SAMPLE_RATIO must be a number, got %q", value))
+		} else {
+			cfg.Tracing.SampleRatio = ratio
+		}
+	}
+
+	if value, ok := lookup("WALLET_STARTING_CREDITS"); ok && value != "" {
+		credits, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("WALLET_STARTING_CREDITS must be a number, got %q", value))
+		} else {
+			cfg.Wallet.StartingCredits = credits
+		}
+	}
+
+	ruleVars := []struct {
+		name   string
+		target *RateLimitRule
+	}{
+		{"RATE_LIMIT_ANONYMOUS", &cfg.RateLimit.Anonymous},
+		{"RATE_LIMIT_BUYER", &cfg.RateLimit.Buyer},
+		{"RATE_LIMIT_ADMIN", &cfg.RateLimit.Admin},
+	}
+	for _, v := range ruleVars {
+		value, ok := lookup(v.name)
+		if !ok || value == "" {
+			continue
+		}
+		rule, err := ParseRateLimitRule(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be written as requests/window such as 60/1m: %s", v.name, err))
+			continue
+		}
+		*v.target = rule
+	}
+
+	if value, ok := lookup("TRUSTED_PROXIES"); ok && value != "" {
+		cfg.Server.TrustedProxies = splitList(value)
+	}
+
+	if value, ok := lookup("CORS_ALLOW_ORIGINS"); ok && value != "" {
+		cfg.Server.CORSOrigins = splitList(value)
+	}
+
+	if len(errs) > 0 {
+		return invalid(errs)
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping blank items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var errs []string
+
+	if _, err := strconv.ParseUint(c.Server.Port, 10, 16); err != nil {
+		errs = append(errs, fmt.Sprintf("PORT must be a valid port number, got %q", c.Server.Port))
+	}
+	if _, err := strconv.ParseUint(c.Server.MetricsPort, 10, 16); err != nil {
+		errs = append(errs, fmt.Sprintf("METRICS_PORT must be a valid port number, got %q", c.Server.MetricsPort))
+	} else if c.Server.MetricsPort == c.Server.Port {
+		errs = append(errs, "METRICS_PORT must differ from PORT, metrics are not served publicly")
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		errs = append(errs, "CORS_ALLOW_ORIGINS must list at least one origin")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, "SHUTDOWN_TIMEOUT must be positive")
+	}
+	// Without trusted proxies any client could set ProxyHeader and pick the IP it is rate limited by
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		errs = append(errs, "TRUSTED_PROXIES is required when PROXY_HEADER is set")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Sprintf("TRUSTED_PROXIES must list IPs or CIDRs, got %q", proxy))
+			}
+		}
+	}
+
+	errs = append(errs, c.Database.validate()...)
+
+	if c.JWT.SecretKey == "" {
+		errs = append(errs, "JWT_SECRET_KEY is required")
+	} else if len(c.JWT.SecretKey) < 32 {
+		errs = append(errs, "JWT_SECRET_KEY must be at least 32 characters long")
+	}
+	if c.JWT.TTL <= 0 {
+		errs = append(errs, "JWT_TTL must be positive")
+	}
+
+	if parsed, err := url.Parse(c.Supplies.URL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		errs = append(errs, fmt.Sprintf("SUPPLIES_URL must be an absolute URL, got %q", c.Supplies.URL))
+	}
+	if c.Supplies.Schedule == "" {
+		errs = append(errs, "SUPPLIES_SCHEDULE is required")
+	}
+	if c.Supplies.Timeout <= 0 {
+		errs = append(errs, "SUPPLIES_TIMEOUT must be positive")
+	}
+	if c.Supplies.MaxAge <= 0 {
+		errs = append(errs, "SUPPLIES_MAX_AGE must be positive")
+	}
+
+	if c.Reservations.TTL <= 0 {
+		errs = append(errs, "RESERVATION_TTL must be positive")
+	}
+	if c.Reservations.ExpirySchedule == "" {
+		errs = append(errs, "RESERVATION_EXPIRY_SCHEDULE is required")
+	}
+	if c.Wallet.StartingCredits < 0 {
+		errs = append(errs, "WALLET_STARTING_CREDITS must not be negative")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Sprintf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Sprintf("LOG_FORMAT must be json or text, got %q", c.Log.Format))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.Tracing.Endpoint == "" {
+			errs = append(errs, "TRACING_ENDPOINT is required by the otlp exporter")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("TRACING_EXPORTER must be none, otlp or stdout, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	if c.Password.MinLength < 1 || c.Password.MinLength > MaxPasswordBytes {
+		errs = append(errs, fmt.Sprintf("PASSWORD_MIN_LENGTH must be between 1 and %d", MaxPasswordBytes))
+	}
+	if c.Password.MinClasses < 0 || c.Password.MinClasses > 4 {
+		errs = append(errs, "PASSWORD_MIN_CLASSES must be between 0 and 4")
+	}
+
+	if c.Tokens.PasswordResetTTL <= 0 {
+		errs = append(errs, "PASSWORD_RESET_TTL must be positive")
+	}
+	if c.Tokens.EmailVerificationTTL <= 0 {
+		errs = append(errs, "EMAIL_VERIFICATION_TTL must be positive")
+	}
+
+	if c.RateLimit.Enabled {
+		for _, rule := range []struct {
+			name string
+			rule RateLimitRule
+		}{
+			{"RATE_LIMIT_ANONYMOUS", c.RateLimit.Anonymous},
+			{"RATE_LIMIT_BUYER", c.RateLimit.Buyer},
+			{"RATE_LIMIT_ADMIN", c.RateLimit.Admin},
+		} {
+			if rule.rule.Requests < 1 || rule.rule.Window <= 0 {
+				errs = append(errs, fmt.Sprintf("%s must allow at least 1 request in a positive window, got %s", rule.name, rule.rule))
+			}
+		}
+	}
+
+	if c.Lockout.MaxAccountFailures < 1 {
+		errs = append(errs, "LOCKOUT_MAX_ACCOUNT_FAILURES must be at least 1")
+	}
+	if c.Lockout.MaxIPFailures < 1 {
+		errs = append(errs, "LOCKOUT_MAX_IP_FAILURES must be at least 1")
+	}
+	if c.Lockout.BaseDuration <= 0 {
+		errs = append(errs, "LOCKOUT_BASE_DURATION must be positive")
+	}
+	if c.Lockout.MaxDuration < c.Lockout.BaseDuration {
+		errs = append(errs, "LOCKOUT_MAX_DURATION must not be shorter than LOCKOUT_BASE_DURATION")
+	}
+	if c.Lockout.Window <= 0 {
+		errs = append(errs, "LOCKOUT_WINDOW must be positive")
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "file":
+		if c.Mail.Path == "" {
+			errs = append(errs, "MAIL_PATH is required by the file mail driver")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("MAIL_DRIVER must be log or file, got %q", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, "MAIL_FROM is required")
+	}
+	if parsed, err := url.Parse(c.Mail.LinkBaseURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		errs = append(errs, fmt.Sprintf("MAIL_LINK_BASE_URL must be an absolute URL, got %q", c.Mail.LinkBaseURL))
+	}
+
+	if len(errs) > 0 {
+		return invalid(errs)
+	}
+	return nil
+}
+
+// validate lists the problems with the database settings
+func (d DatabaseConfig) validate() []string {
+	var errs []string
+	if d.Host == "" {
+		errs = append(errs, "DB_HOST is required")
+	}
+	if d.User == "" {
+		errs = append(errs, "DB_USER is required")
+	}
+	if d.Name == "" {
+		errs = append(errs, "DB_NAME is required")
+	}
+	if _, err := strconv.ParseUint(d.Port, 10, 16); err != nil {
+		errs = append(errs, fmt.Sprintf("DB_PORT must be a valid port number, got %q", d.Port))
+	}
+	switch d.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Sprintf("DB_SSLMODE %q is not a valid Postgres sslmode", d.SSLMode))
+	}
+	return errs
+}
+
+// invalid joins the validation problems in errs into a single error
+func invalid(errs []string) error {
+	return errors.New("invalid configuration:\n  - " + strings.Join(errs, "\n  - "))
+}
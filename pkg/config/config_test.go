@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true") // Skip loading the developer's .env file
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "new_world_lab3")
+	t.Setenv("JWT_SECRET_KEY", "a6a6d01782e0cb082ad4b016a508d4a913c2556f38aa26303d0d00562e111aaa")
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("DB_SSLMODE", "require")
+	t.Setenv("RESERVATION_TTL", "5m")
+	t.Setenv("RESERVATION_EXPIRY_SCHEDULE", "@every 30s")
+	t.Setenv("WALLET_STARTING_CREDITS", "50")
+	t.Setenv("SHUTDOWN_TIMEOUT", "45s")
+	t.Setenv("CORS_ALLOW_ORIGINS", "http://a.localhost, http://b.localhost")
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("PASSWORD_MIN_LENGTH", "14")
+	t.Setenv("PASSWORD_CHECK_COMMON", "false")
+	t.Setenv("MAIL_DRIVER", "file")
+	t.Setenv("PASSWORD_RESET_TTL", "30m")
+	t.Setenv("RATE_LIMIT_BUYER", "10/30s")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 172.16.0.1")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+
+	assert.Equal(t, "3000", cfg.Server.Port)
+	assert.Equal(t, []string{"http://a.localhost", "http://b.localhost"}, cfg.Server.CORSOrigins)
+	assert.Equal(t, config.ReservationsConfig{TTL: 5 * time.Minute, ExpirySchedule: "@every 30s"}, cfg.Reservations)
+	assert.Equal(t, 50.0, cfg.Wallet.StartingCredits)
+	assert.Equal(t, 45*time.Second, cfg.Server.ShutdownTimeout)
+	assert.Equal(t, "otlp", cfg.Tracing.Exporter)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.Equal(t, config.PasswordConfig{MinLength: 14, MinClasses: 3, CheckCommon: false}, cfg.Password)
+	assert.Equal(t, "file", cfg.Mail.Driver)
+	assert.Equal(t, "mail.log", cfg.Mail.Path)
+	assert.Equal(t, 30*time.Minute, cfg.Tokens.PasswordResetTTL)
+	assert.Equal(t, config.RateLimitRule{Requests: 10, Window: 30 * time.Second}, cfg.RateLimit.Buyer)
+	assert.Equal(t, []string{"10.0.0.0/8", "172.16.0.1"}, cfg.Server.TrustedProxies)
+	assert.Equal(t, "host=localhost user=postgres password= dbname=new_world_lab3 sslmode=require port=5432", cfg.Database.DSN())
+}
+
+func TestLoadYAMLIsOverriddenByEnv(t *testing.T) {
+	setRequiredEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "server:\n  port: \"4000\"\njwt:\n  ttl: 2h\ndatabase:\n  host: yaml-host\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+
+	assert.Equal(t, "4000", cfg.Server.Port)
+	assert.Equal(t, 2*time.Hour, cfg.JWT.TTL)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.SSLMode = "sometimes"
+	cfg.Log.Level = "verbose"
+	cfg.Password.MinLength = 100
+	cfg.Server.MetricsPort = cfg.Server.Port
+
+	err := cfg.Validate()
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "DB_HOST is required")
+		assert.Contains(t, err.Error(), "JWT_SECRET_KEY is required")
+		assert.Contains(t, err.Error(), `DB_SSLMODE "sometimes" is not a valid Postgres sslmode`)
+		assert.Contains(t, err.Error(), `LOG_LEVEL must be debug, info, warn or error, got "verbose"`)
+		assert.Contains(t, err.Error(), "PASSWORD_MIN_LENGTH must be between 1 and 72")
+		assert.Contains(t, err.Error(), "METRICS_PORT must differ from PORT")
+	}
+}
+
+func TestLoadRejectsInvalidDuration(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("JWT_TTL", "tomorrow")
+
+	_, err := config.Load()
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `JWT_TTL must be a duration such as 15m or 24h, got "tomorrow"`)
+	}
+}
+
+func TestLoadRejectsInvalidRateLimit(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("RATE_LIMIT_ADMIN", "lots")
+
+	_, err := config.Load()
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "RATE_LIMIT_ADMIN must be written as requests/window")
+	}
+}
+
+func TestLoadDatabaseIgnoresServerSettings(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER", "postgres")
+	t.Setenv("DB_NAME", "new_world_lab3")
+	t.Setenv("JWT_SECRET_KEY", "")
+	t.Setenv("LOG_LEVEL", "verbose")
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		t.Fatalf("Failed to load database config: %s", err)
+	}
+	assert.Equal(t, "host=localhost user=postgres password= dbname=new_world_lab3 sslmode=disable port=5432", cfg.DSN())
+
+	t.Setenv("DB_HOST", "")
+	_, err = config.LoadDatabase()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "DB_HOST is required")
+		assert.NotContains(t, err.Error(), "JWT_SECRET_KEY")
+	}
+}
+
+func TestValidateRequiresTrustedProxiesWithProxyHeader(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("PROXY_HEADER", "X-Forwarded-For")
+
+	_, err := config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "TRUSTED_PROXIES is required when PROXY_HEADER is set")
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "172.28.0.0/16, traefik")
+	_, err = config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `TRUSTED_PROXIES must list IPs or CIDRs, got "traefik"`)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "172.28.0.0/16, 10.0.0.1")
+	cfg, err := config.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "X-Forwarded-For", cfg.Server.ProxyHeader)
+	}
+}
@@ -0,0 +1,75 @@
+// pkg/container/container.go
+
+package container
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/lockout"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/mailer"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/ratelimit"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"gorm.io/gorm"
+)
+
+// Container holds the dependencies shared by controllers and jobs, wired once in main
+type Container struct {
+	DB           *gorm.DB
+	Config       *config.Config
+	Clock        clock.Clock
+	Supplies     utils.SuppliesProvider
+	SuppliesSync *utils.SuppliesSync
+	Validator    *validation.Validator
+	Mailer       mailer.Mailer
+	LoginGuard   *lockout.Guard
+	RateLimits   ratelimit.Store
+
+	UserService        *services.UserService
+	OrderService       *services.OrderService
+	CheckoutService    *services.CheckoutService
+	ReservationService *services.ReservationService
+	WalletService      *services.WalletService
+	RationingService   *services.RationingService
+	CommunityService   *services.CommunityService
+	DeliveryService    *services.DeliveryService
+	AccountService     *services.AccountService
+	APIKeyService      *services.APIKeyService
+}
+
+// New wires the production dependencies around an open database handle
+func New(cfg *config.Config, db *gorm.DB) *Container {
+	return WithStore(cfg, db, clock.Real{}, repositories.NewGormStore(db))
+}
+
+// WithStore wires the dependencies around the given clock and repository store,
+// so tests can run the services on fakes
+func WithStore(cfg *config.Config, db *gorm.DB, clk clock.Clock, store repositories.Store) *Container {
+	supplies := utils.NewHTTPSuppliesProvider(cfg.Supplies)
+	validator := validation.New(store, cfg.Password)
+	mail := mailer.New(cfg.Mail)
+	checkout := services.NewCheckoutService(store, clk)
+	return &Container{
+		DB:                 db,
+		Config:             cfg,
+		Clock:              clk,
+		Supplies:           supplies,
+		SuppliesSync:       utils.NewSuppliesSync(db, supplies, clk),
+		Validator:          validator,
+		Mailer:             mail,
+		LoginGuard:         lockout.NewGuard(cfg.Lockout, lockout.NewMemoryStore(), clk),
+		RateLimits:         ratelimit.NewMemoryStore(),
+		UserService:        services.NewUserService(store, validator, clk, cfg.Wallet),
+		OrderService:       services.NewOrderService(store, clk),
+		CheckoutService:    checkout,
+		ReservationService: services.NewReservationService(store, checkout, clk, cfg.Reservations),
+		WalletService:      services.NewWalletService(store),
+		RationingService:   services.NewRationingService(store),
+		CommunityService:   services.NewCommunityService(store),
+		DeliveryService:    services.NewDeliveryService(store, clk),
+		AccountService:     services.NewAccountService(store, validator, mail, clk, cfg.Tokens, cfg.Mail),
+		APIKeyService:      services.NewAPIKeyService(store, validator, clk),
+	}
+}
@@ -3,42 +3,40 @@
 package database
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
-	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// OpenDB opens a connection pool without touching the schema. Unique violations are reported
+// as gorm.ErrDuplicatedKey so repositories can tell them from other failures.
+func OpenDB(connStr string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(connStr), &gorm.Config{TranslateError: true})
+}
 
-func InitDB(connStr string) {
-	var err error
-	DB, err = gorm.Open(postgres.Open(connStr), &gorm.Config{})
+// InitDB opens the connection pool and verifies the schema is at the expected version
+func InitDB(connStr string) *gorm.DB {
+	db, err := OpenDB(connStr)
 	if err != nil {
-		log.Fatal("Failed to connect to the database: ", err)
+		slog.Error("Failed to connect to the database", "error", err)
+		os.Exit(1)
 	}
 
-	// Auto migrate models
-	err = DB.AutoMigrate(&models.User{}, &models.Offer{}, &models.Order{}, &models.OrderItem{})
-	if err != nil {
-		log.Fatal("Failed to migrate database: ", err)
+	// The schema is managed by the `migrate` subcommand, refuse to serve on a mismatch
+	if err := CheckSchemaVersion(db); err != nil {
+		slog.Error("Failed to verify database schema", "error", err)
+		os.Exit(1)
 	}
+	return db
 }
 
-func GetDB() *gorm.DB {
-	return DB
-}
-
-func CloseDB() {
-	sqlDB, err := DB.DB()
+// CloseDB closes the connection pool, waiting for the queries in progress to finish
+func CloseDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to close database connection: ", err)
+		return err
 	}
-	sqlDB.Close()
-}
-
-// SetDB sets the database connection (useful for testing)
-func SetDB(database *gorm.DB) {
-	DB = database
+	return sqlDB.Close()
 }
@@ -0,0 +1,123 @@
+package database_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := database.LoadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %s", err)
+	}
+
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		// Versions must be consecutive so `migrate down` reverts them in the order they were applied
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+
+	latest, err := database.LatestVersion()
+	if err != nil {
+		t.Fatalf("Failed to read latest version: %s", err)
+	}
+	assert.Equal(t, migrations[len(migrations)-1].Version, latest)
+}
+
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to set up mock database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  "user=gorm dbname=gorm sslmode=disable",
+		DriverName:           "postgres",
+		Conn:                 db,
+		PreferSimpleProtocol: true,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm db: %s", err)
+	}
+	return gormDB, mock
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	migrations, err := database.LoadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %s", err)
+	}
+	var all []int
+	for _, migration := range migrations {
+		all = append(all, migration.Version)
+	}
+	latest := migrations[len(migrations)-1]
+
+	tests := []struct {
+		name    string
+		applied []int
+		err     string
+	}{
+		{"up to date", all, ""},
+		{"pending migration", all[:len(all)-1], fmt.Sprintf("pending migrations: %d_%s", latest.Version, latest.Name)},
+		{"newer schema", append(all, latest.Version+1), fmt.Sprintf("version %d is newer than the %d", latest.Version+1, latest.Version)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			rows := sqlmock.NewRows([]string{"version"})
+			for _, version := range tt.applied {
+				rows.AddRow(version)
+			}
+			// Only schema_migrations is read, the migration lock is never taken
+			mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(rows)
+
+			err := database.CheckSchemaVersion(db)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCheckSchemaVersionWithoutMigrationsTable(t *testing.T) {
+	db, mock := setupMockDB(t)
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).
+		WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+	err := database.CheckSchemaVersion(db)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "run `migrate up`")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWriteStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	var out bytes.Buffer
+
+	err := database.WriteStatus(&out, []database.MigrationState{
+		{Version: 1, Name: "initial_schema", AppliedAt: &appliedAt},
+		{Version: 12, Name: "deliveries"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0001_initial_schema\tapplied 2026-10-19T08:30:00Z\n0012_deliveries\tpending\n", out.String())
+}
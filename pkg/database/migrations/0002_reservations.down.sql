@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reservation_items;
+DROP TABLE IF EXISTS reservations;
+ALTER TABLE orders DROP COLUMN IF EXISTS user_id;
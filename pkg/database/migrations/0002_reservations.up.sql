@@ -0,0 +1,30 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS user_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id    BIGINT NOT NULL,
+    status     TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    order_id   BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_deleted_at ON reservations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_reservations_user_id ON reservations (user_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations (status);
+CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations (expires_at);
+
+CREATE TABLE IF NOT EXISTS reservation_items (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ,
+    reservation_id BIGINT NOT NULL REFERENCES reservations (id),
+    offer_id       BIGINT NOT NULL,
+    quantity       BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_reservation_items_deleted_at ON reservation_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_reservation_items_reservation_id ON reservation_items (reservation_id);
+CREATE INDEX IF NOT EXISTS idx_reservation_items_offer_id ON reservation_items (offer_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS rationing_rules (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ,
+    offer_id     BIGINT,
+    category     VARCHAR(50),
+    max_quantity BIGINT NOT NULL,
+    window_hours BIGINT NOT NULL,
+    description  TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_rationing_rules_deleted_at ON rationing_rules (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_rationing_rules_offer_id ON rationing_rules (offer_id);
+CREATE INDEX IF NOT EXISTS idx_rationing_rules_category ON rationing_rules (category);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wallet_transactions;
+DROP TABLE IF EXISTS wallets;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_status;
@@ -0,0 +1,27 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_status TEXT;
+
+CREATE TABLE IF NOT EXISTS wallets (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id    BIGINT NOT NULL,
+    balance    DECIMAL NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets (user_id);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    user_id     BIGINT NOT NULL,
+    amount      DECIMAL NOT NULL,
+    type        TEXT NOT NULL,
+    order_id    BIGINT,
+    description TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_deleted_at ON wallet_transactions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_user_id ON wallet_transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_order_id ON wallet_transactions (order_id);
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS community_id;
+DROP TABLE IF EXISTS community_settlements;
+DROP TABLE IF EXISTS community_representatives;
+DROP TABLE IF EXISTS communities;
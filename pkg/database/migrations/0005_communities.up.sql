@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS communities (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    name       TEXT NOT NULL,
+    location   TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_communities_name ON communities (name);
+CREATE INDEX IF NOT EXISTS idx_communities_deleted_at ON communities (deleted_at);
+
+CREATE TABLE IF NOT EXISTS community_representatives (
+    community_id BIGINT NOT NULL REFERENCES communities (id),
+    user_id      BIGINT NOT NULL REFERENCES users (id),
+    PRIMARY KEY (community_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS community_settlements (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ,
+    community_id BIGINT NOT NULL,
+    amount       DECIMAL NOT NULL,
+    description  TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_community_settlements_deleted_at ON community_settlements (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_community_settlements_community_id ON community_settlements (community_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS community_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_orders_community_id ON orders (community_id);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS delivery_slots (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    starts_at  TIMESTAMPTZ NOT NULL,
+    ends_at    TIMESTAMPTZ NOT NULL,
+    capacity   BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_delivery_slots_deleted_at ON delivery_slots (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_delivery_slots_starts_at ON delivery_slots (starts_at);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ,
+    order_id     BIGINT NOT NULL,
+    slot_id      BIGINT NOT NULL REFERENCES delivery_slots (id),
+    courier_id   BIGINT NOT NULL,
+    destination  TEXT NOT NULL,
+    notes        TEXT,
+    delivered_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deliveries_order_id ON deliveries (order_id);
+CREATE INDEX IF NOT EXISTS idx_deliveries_deleted_at ON deliveries (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_deliveries_slot_id ON deliveries (slot_id);
+CREATE INDEX IF NOT EXISTS idx_deliveries_courier_id ON deliveries (courier_id);
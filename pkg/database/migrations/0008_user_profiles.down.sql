@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS delivery_location;
+ALTER TABLE users DROP COLUMN IF EXISTS sector;
+ALTER TABLE users DROP COLUMN IF EXISTS shelter;
@@ -0,0 +1,2 @@
+-- Normalized usernames and emails cannot be told apart from the ones typed that way, so they are kept
+DROP FUNCTION IF EXISTS normalize_identity(TEXT);
@@ -0,0 +1,38 @@
+-- Usernames and emails are stored normalized: Unicode NFKC, lower case and without surrounding
+-- spaces, the steps of identity.Normalize
+CREATE OR REPLACE FUNCTION normalize_identity(value TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE STRICT
+    AS $$ SELECT btrim(lower(normalize(value, NFKC)), E' \t\n\r\f') $$;
+
+-- Deleted users are left out of the unique indexes, so theirs cannot collide
+UPDATE users SET username = normalize_identity(username), email = normalize_identity(email)
+WHERE deleted_at IS NOT NULL;
+
+-- Among the users whose username or email is the same once normalized, only the one already holding
+-- the normalized form, or else the oldest, gets it. The others keep theirs as typed until an admin
+-- sorts them out, GET /admin/users/collisions lists them.
+WITH ranked AS (
+    SELECT id, normalize_identity(username) AS normalized,
+        row_number() OVER (
+            PARTITION BY normalize_identity(username)
+            ORDER BY username = normalize_identity(username) DESC, id
+        ) AS rank
+    FROM users
+    WHERE deleted_at IS NULL
+)
+UPDATE users SET username = ranked.normalized
+FROM ranked
+WHERE users.id = ranked.id AND ranked.rank = 1 AND users.username <> ranked.normalized;
+
+WITH ranked AS (
+    SELECT id, normalize_identity(email) AS normalized,
+        row_number() OVER (
+            PARTITION BY normalize_identity(email)
+            ORDER BY email = normalize_identity(email) DESC, id
+        ) AS rank
+    FROM users
+    WHERE deleted_at IS NULL
+)
+UPDATE users SET email = ranked.normalized
+FROM ranked
+WHERE users.id = ranked.id AND ranked.rank = 1 AND users.email <> ranked.normalized;
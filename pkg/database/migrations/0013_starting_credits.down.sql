@@ -0,0 +1,17 @@
+-- Takes back what is left of the seed granted by the up migration. Credits already spent stay
+-- spent, so no balance goes negative, and the withdrawal is recorded as a reversing transaction
+-- next to the grant instead of deleting the wallet history.
+WITH granted AS (
+    SELECT wallets.user_id, LEAST(wallets.balance, 100) AS amount
+    FROM wallets
+    JOIN wallet_transactions ON wallet_transactions.user_id = wallets.user_id
+    WHERE wallet_transactions.description = 'Starting credits (migration 0013)'
+), taken AS (
+    UPDATE wallets SET balance = wallets.balance - granted.amount, updated_at = NOW()
+    FROM granted
+    WHERE wallets.user_id = granted.user_id AND granted.amount > 0
+    RETURNING wallets.user_id, granted.amount
+)
+INSERT INTO wallet_transactions (created_at, updated_at, user_id, amount, type, description)
+SELECT NOW(), NOW(), taken.user_id, -taken.amount, 'grant', 'Starting credits reverted (migration 0013)'
+FROM taken;
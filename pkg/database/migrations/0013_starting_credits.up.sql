@@ -0,0 +1,15 @@
+-- Users registered before the wallet existed have no credits to check out with, so every user
+-- without a wallet gets a one-off seed of 100 credits. Migrations run without the server
+-- configuration, so the seed is fixed here and does not follow WALLET_STARTING_CREDITS, which
+-- only applies to users registering from now on.
+WITH created AS (
+    INSERT INTO wallets (created_at, updated_at, user_id, balance)
+    SELECT NOW(), NOW(), users.id, 100
+    FROM users
+    WHERE users.deleted_at IS NULL
+      AND NOT EXISTS (SELECT 1 FROM wallets WHERE wallets.user_id = users.id)
+    RETURNING user_id
+)
+INSERT INTO wallet_transactions (created_at, updated_at, user_id, amount, type, description)
+SELECT NOW(), NOW(), created.user_id, 100, 'grant', 'Starting credits (migration 0013)'
+FROM created;
@@ -0,0 +1,21 @@
+// pkg/identity/identity.go
+
+package identity
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the form usernames and emails are stored and looked up in: Unicode NFKC,
+// lower case and without surrounding spaces, so `Joel@Shelter.org`, ` joel@shelter.org` and
+// `ｊｏｅｌ@shelter.org` are the same account. Migration 0011 applies the same steps in SQL.
+func Normalize(value string) string {
+	return strings.TrimSpace(strings.ToLower(norm.NFKC.String(value)))
+}
+
+// IsNormalized reports whether value is already stored in normalized form
+func IsNormalized(value string) bool {
+	return Normalize(value) == value
+}
@@ -0,0 +1,27 @@
+// pkg/identity/identity_test.go
+package identity_test
+
+import (
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"joel@shelter.org":     "joel@shelter.org",
+		"  Joel@Shelter.ORG\t": "joel@shelter.org",
+		"ｊｏｅｌ@shelter.org":     "joel@shelter.org", // Fullwidth letters
+		"\ufb01nn":             "finn",             // Ligature
+		"Ellie\u00a0":          "ellie",            // No-break space
+		"Zo\u00eb":             "zo\u00eb",
+		"Zoe\u0308":            "zo\u00eb", // Combining diaeresis
+	}
+	for value, want := range cases {
+		assert.Equal(t, want, identity.Normalize(value), value)
+	}
+
+	assert.True(t, identity.IsNormalized("joel@shelter.org"))
+	assert.False(t, identity.IsNormalized("Joel@shelter.org"))
+}
@@ -0,0 +1,232 @@
+// pkg/lockout/lockout.go
+
+package lockout
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/identity"
+)
+
+// Kinds of subject failed logins are counted for
+const (
+	KindAccount = "account"
+	KindIP      = "ip"
+)
+
+// Entry counts the failed logins of an account or a client IP
+type Entry struct {
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject"`      // Email of the account or client IP
+	Failures    int       `json:"failures"`     // Failed logins since the last lockout
+	Lockouts    int       `json:"lockouts"`     // Lockouts in a row, each one twice as long as the previous
+	LastFailure time.Time `json:"last_failure"` // When the last failed login happened
+	LockedUntil time.Time `json:"locked_until"` // Logins are refused until this instant
+}
+
+// Key identifies the entry of subject
+func Key(kind, subject string) string {
+	return kind + ":" + subject
+}
+
+// Locked reports whether the entry refuses logins at now
+func (e Entry) Locked(now time.Time) bool {
+	return now.Before(e.LockedUntil)
+}
+
+// Store keeps the entries. The in-memory store only counts the logins served by one replica,
+// replicas that should share their counters need a backend such as Redis or Postgres.
+type Store interface {
+	// Update applies fn to the entry stored under key, or to an empty one, and saves the result.
+	// Updates of the same key must not interleave.
+	Update(key string, fn func(entry *Entry)) Entry
+	Get(key string) (Entry, bool)
+	// Delete removes the entry stored under key and reports whether there was one
+	Delete(key string) bool
+	List() []Entry
+	// Prune removes the entries that keep is false for
+	Prune(keep func(Entry) bool)
+}
+
+// Guard locks accounts and client IPs out after too many failed logins
+type Guard struct {
+	cfg   config.LockoutConfig
+	store Store
+	clock clock.Clock
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewGuard creates a guard that counts failures in store
+func NewGuard(cfg config.LockoutConfig, store Store, clk clock.Clock) *Guard {
+	return &Guard{cfg: cfg, store: store, clock: clk}
+}
+
+// Check returns how long a login for email from ip must wait, zero when it may go ahead
+func (g *Guard) Check(email, ip string) time.Duration {
+	now := g.clock.Now()
+	var wait time.Duration
+	for _, key := range []string{Key(KindAccount, identity.Normalize(email)), Key(KindIP, ip)} {
+		if entry, ok := g.store.Get(key); ok && entry.Locked(now) {
+			if remaining := entry.LockedUntil.Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	return wait
+}
+
+// Fail records a failed login for email from ip
+func (g *Guard) Fail(email, ip string) {
+	now := g.clock.Now()
+	g.fail(KindAccount, identity.Normalize(email), g.cfg.MaxAccountFailures, now)
+	g.fail(KindIP, ip, g.cfg.MaxIPFailures, now)
+	g.prune(now)
+}
+
+// Succeed forgets the failed logins of email. Those of the IP are kept, so a client
+// guessing the passwords of many accounts is still locked out.
+func (g *Guard) Succeed(email string) {
+	g.store.Delete(Key(KindAccount, identity.Normalize(email)))
+}
+
+// Lockouts lists the entries with recent failures or an active lockout, the locked ones first
+func (g *Guard) Lockouts() []Entry {
+	now := g.clock.Now()
+	var entries []Entry
+	for _, entry := range g.store.List() {
+		if g.active(entry, now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Locked(now) != entries[j].Locked(now) {
+			return entries[i].Locked(now)
+		}
+		return entries[i].LastFailure.After(entries[j].LastFailure)
+	})
+	return entries
+}
+
+// Clear forgets the failures and lockouts of subject and reports whether it had any
+func (g *Guard) Clear(kind, subject string) bool {
+	if kind == KindAccount {
+		subject = identity.Normalize(subject)
+	}
+	return g.store.Delete(Key(kind, subject))
+}
+
+func (g *Guard) fail(kind, subject string, max int, now time.Time) {
+	g.store.Update(Key(kind, subject), func(entry *Entry) {
+		if !g.active(*entry, now) {
+			// A quiet window after the last lockout forgives the previous ones
+			*entry = Entry{}
+		}
+		entry.Kind, entry.Subject = kind, subject
+		entry.Failures++
+		entry.LastFailure = now
+		if entry.Failures >= max {
+			entry.Lockouts++
+			entry.Failures = 0
+			entry.LockedUntil = now.Add(g.lockoutDuration(entry.Lockouts))
+		}
+	})
+}
+
+// lockoutDuration doubles the base duration with every lockout in a row, up to the maximum
+func (g *Guard) lockoutDuration(lockouts int) time.Duration {
+	duration := g.cfg.BaseDuration
+	for i := 1; i < lockouts && duration < g.cfg.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > g.cfg.MaxDuration {
+		duration = g.cfg.MaxDuration
+	}
+	return duration
+}
+
+// active reports whether entry still matters at now: it is locked or failed within the window
+func (g *Guard) active(entry Entry, now time.Time) bool {
+	if entry.Locked(now) {
+		return true
+	}
+	last := entry.LastFailure
+	if entry.LockedUntil.After(last) {
+		last = entry.LockedUntil
+	}
+	return !last.IsZero() && now.Sub(last) < g.cfg.Window
+}
+
+// prune drops the forgotten entries once per window, so a client spraying IPs or emails
+// cannot grow the store without bound
+func (g *Guard) prune(now time.Time) {
+	g.mu.Lock()
+	if now.Sub(g.lastPrune) < g.cfg.Window {
+		g.mu.Unlock()
+		return
+	}
+	g.lastPrune = now
+	g.mu.Unlock()
+
+	g.store.Prune(func(entry Entry) bool { return g.active(entry, now) })
+}
+
+// MemoryStore keeps the entries in the memory of the process
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Update(key string, fn func(entry *Entry)) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entries[key]
+	fn(&entry)
+	s.entries[key] = entry
+	return entry
+}
+
+func (s *MemoryStore) Get(key string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	return entry, ok
+}
+
+func (s *MemoryStore) Delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.entries[key]
+	delete(s.entries, key)
+	return ok
+}
+
+func (s *MemoryStore) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (s *MemoryStore) Prune(keep func(Entry) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, entry := range s.entries {
+		if !keep(entry) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// pkg/lockout/lockout_test.go
+package lockout_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/lockout"
+	"github.com/stretchr/testify/assert"
+)
+
+// movingClock is a clock tests can move forward
+type movingClock struct {
+	now time.Time
+}
+
+func (c *movingClock) Now() time.Time { return c.now }
+
+func setupGuard() (*lockout.Guard, *movingClock) {
+	clk := &movingClock{now: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+	cfg := config.LockoutConfig{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		BaseDuration:       time.Minute,
+		MaxDuration:        5 * time.Minute,
+		Window:             time.Hour,
+	}
+	return lockout.NewGuard(cfg, lockout.NewMemoryStore(), clk), clk
+}
+
+func TestAccountLockoutIsProgressive(t *testing.T) {
+	guard, clk := setupGuard()
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		for i := 0; i < 3; i++ {
+			assert.Zero(t, guard.Check("Mia@Example.com", "10.0.0.1"))
+			// Every attempt comes from a different IP, so only the account counter locks
+			guard.Fail("mia@example.com ", fmt.Sprintf("10.0.%d.%d", want/time.Minute, i))
+		}
+		assert.Equal(t, want, guard.Check("MIA@example.com", "10.0.0.9"))
+		clk.now = clk.now.Add(want)
+	}
+}
+
+func TestSuccessClearsTheAccountButNotTheIP(t *testing.T) {
+	guard, _ := setupGuard()
+
+	for i := 0; i < 2; i++ {
+		guard.Fail("mia@example.com", "10.0.0.1")
+	}
+	guard.Succeed("mia@example.com")
+	for i := 0; i < 3; i++ {
+		guard.Fail(fmt.Sprintf("other%d@example.com", i), "10.0.0.1")
+	}
+
+	assert.Zero(t, guard.Check("mia@example.com", "10.0.0.2"))
+	assert.Equal(t, time.Minute, guard.Check("mia@example.com", "10.0.0.1"))
+}
+
+func TestFailuresAreForgottenAfterTheWindow(t *testing.T) {
+	guard, clk := setupGuard()
+
+	guard.Fail("mia@example.com", "10.0.0.1")
+	guard.Fail("mia@example.com", "10.0.0.1")
+	clk.now = clk.now.Add(time.Hour)
+	guard.Fail("mia@example.com", "10.0.0.1")
+
+	assert.Zero(t, guard.Check("mia@example.com", "10.0.0.1"))
+	assert.Equal(t, 1, guard.Lockouts()[0].Failures)
+}
+
+func TestLockoutsCanBeListedAndCleared(t *testing.T) {
+	guard, _ := setupGuard()
+
+	for i := 0; i < 3; i++ {
+		guard.Fail("mia@example.com", "10.0.0.1")
+	}
+
+	entries := guard.Lockouts()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, lockout.KindAccount, entries[0].Kind)
+		assert.Equal(t, "mia@example.com", entries[0].Subject)
+		assert.Equal(t, 1, entries[0].Lockouts)
+		assert.Equal(t, lockout.KindIP, entries[1].Kind)
+		assert.Equal(t, 3, entries[1].Failures)
+	}
+
+	assert.True(t, guard.Clear(lockout.KindAccount, "Mia@example.com"))
+	assert.False(t, guard.Clear(lockout.KindAccount, "mia@example.com"))
+	assert.Zero(t, guard.Check("mia@example.com", "10.0.0.2"))
+}
@@ -0,0 +1,25 @@
+// pkg/logger/logger.go
+
+package logger
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+)
+
+// New builds the structured logger that writes to w with the level and format in cfg.
+// The configuration is validated on load, so an unknown level falls back to info.
+func New(cfg config.LogConfig, w io.Writer) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
@@ -0,0 +1,81 @@
+// pkg/mailer/mailer.go
+
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+)
+
+// Message is an email sent to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages to users. The refuge has no SMTP server, so the default
+// implementations keep the messages where an operator can read and forward them.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Driver
+func New(cfg config.MailConfig) Mailer {
+	if cfg.Driver == "file" {
+		return NewFileMailer(cfg.Path, cfg.From)
+	}
+	return NewLogMailer(cfg.From)
+}
+
+// LogMailer writes every message to the structured log
+type LogMailer struct {
+	from string
+}
+
+// NewLogMailer creates a mailer that logs messages sent by from
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "Mail sent", "from", m.from, "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// FileMailer appends every message to a local file in mbox-like format
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+	from string
+	now  func() time.Time
+}
+
+// NewFileMailer creates a mailer that appends messages sent by from to path, creating it if needed
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{path: path, from: from, now: time.Now}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "From: %s\nTo: %s\nDate: %s\nSubject: %s\n\n%s\n\n",
+		m.from, msg.To, m.now().UTC().Format(time.RFC1123Z), msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
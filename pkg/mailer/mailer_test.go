@@ -0,0 +1,32 @@
+// pkg/mailer/mailer_test.go
+package mailer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailerAppendsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := mailer.NewFileMailer(path, "no-reply@newworld.local")
+
+	for _, to := range []string{"ana@example.com", "leo@example.com"} {
+		err := m.Send(context.Background(), mailer.Message{To: to, Subject: "Verify your email", Body: "token"})
+		if err != nil {
+			t.Fatalf("Failed to send mail: %s", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read mail file: %s", err)
+	}
+	assert.Contains(t, string(content), "From: no-reply@newworld.local\nTo: ana@example.com\n")
+	assert.Contains(t, string(content), "To: leo@example.com\n")
+	assert.Contains(t, string(content), "Subject: Verify your email\n\ntoken\n")
+}
@@ -0,0 +1,124 @@
+// pkg/metrics/metrics.go
+
+package metrics
+
+import (
+	"log/slog"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const namespace = "market"
+
+// Registry holds every metric exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts the handled requests by method, route pattern and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes the latency of the handled requests by method, route pattern and status
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// CheckoutOutcomes counts checkouts by outcome: "success" or the reason they failed
+	CheckoutOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkouts_total",
+		Help:      "Checkouts attempted, by outcome (success or failure reason).",
+	}, []string{"outcome"})
+
+	// RateLimited counts the requests rejected for exceeding the limit of their route group
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests rejected by the rate limiter, by route group.",
+	}, []string{"group"})
+
+	// SuppliesSyncs counts the supplies syncs by result: "success" or "failure"
+	SuppliesSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "supplies_syncs_total",
+		Help:      "Supplies syncs run, by result.",
+	}, []string{"result"})
+
+	// SuppliesSyncDuration observes how long the supplies syncs take
+	SuppliesSyncDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "supplies_sync_duration_seconds",
+		Help:      "Duration of the supplies syncs.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	})
+
+	// SuppliesLastSuccess is the Unix time of the last successful supplies sync
+	SuppliesLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "supplies_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful supplies sync.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		CheckoutOutcomes,
+		RateLimited,
+		SuppliesSyncs,
+		SuppliesSyncDuration,
+		SuppliesLastSuccess,
+	)
+}
+
+// RegisterDB adds the connection pool statistics and the stock of every offer, read at scrape time
+func RegisterDB(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		slog.Error("Failed to register database metrics", "error", err)
+		return
+	}
+	Registry.MustRegister(
+		collectors.NewDBStatsCollector(sqlDB, "postgres"),
+		&stockCollector{db: db},
+	)
+}
+
+var offerStockDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "offer_stock"),
+	"Units in stock of every offer.",
+	[]string{"offer", "category"}, nil,
+)
+
+// stockCollector reports the stock of the offers straight from the database on every scrape
+type stockCollector struct {
+	db *gorm.DB
+}
+
+func (sc *stockCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- offerStockDesc
+}
+
+func (sc *stockCollector) Collect(ch chan<- prometheus.Metric) {
+	var offers []models.Offer
+	if err := sc.db.Find(&offers).Error; err != nil {
+		ch <- prometheus.NewInvalidMetric(offerStockDesc, err)
+		return
+	}
+	for _, offer := range offers {
+		ch <- prometheus.MustNewConstMetric(offerStockDesc, prometheus.GaugeValue,
+			float64(offer.Quantity), offer.Name, offer.Category)
+	}
+}
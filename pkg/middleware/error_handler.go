@@ -0,0 +1,60 @@
+// pkg/middleware/error_handler.go
+
+package middleware
+
+import (
+	"strings"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details
+const MIMEProblemJSON = "application/problem+json"
+
+// problemTypePrefix namespaces the error codes in the type member of problem details
+const problemTypePrefix = "urn:newworld:error:"
+
+// ErrorHandler renders every error returned by handlers and middleware as an ErrorResponse,
+// or as RFC 7807 problem details when the client accepts application/problem+json.
+// Internal errors are logged with their cause, which is never sent to the client.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	apiErr := apierror.From(err)
+	requestID := GetRequestID(c)
+
+	if apiErr.Status >= fiber.StatusInternalServerError {
+		args := []any{"code", apiErr.Code, "message", apiErr.Message}
+		if apiErr.Cause != nil {
+			args = append(args, "error", apiErr.Cause)
+		}
+		RequestLogger(c).Error("Request failed", args...)
+	}
+
+	if acceptsProblem(c) {
+		return c.Status(apiErr.Status).JSON(models.ProblemResponse{
+			Type:      problemTypePrefix + apiErr.Code,
+			Title:     utils.StatusMessage(apiErr.Status),
+			Status:    apiErr.Status,
+			Detail:    apiErr.Message,
+			Instance:  c.OriginalURL(),
+			Code:      apiErr.Code,
+			Errors:    apiErr.Details,
+			RequestID: requestID,
+		}, MIMEProblemJSON)
+	}
+
+	return c.Status(apiErr.Status).JSON(models.ErrorResponse{
+		Code:      apiErr.Status,
+		Error:     apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: requestID,
+	})
+}
+
+// acceptsProblem tells whether the client asked for problem details instead of the default envelope
+func acceptsProblem(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), MIMEProblemJSON)
+}
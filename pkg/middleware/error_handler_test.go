@@ -0,0 +1,72 @@
+// pkg/middleware/error_handler_test.go
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandlerRendersEnvelope(t *testing.T) {
+	resp, err := setupApp().Test(httptest.NewRequest("GET", "/bad", nil))
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+
+	var body models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response body: %s", err)
+	}
+	assert.Equal(t, apierror.CodeValidationFailed, body.Error)
+	assert.Equal(t, []models.FieldError{{Field: "quantity", Rule: "gt", Message: "must be greater than 0"}}, body.Details)
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	resp, err := setupApp().Test(httptest.NewRequest("GET", "/boom", nil))
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+
+	var body models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response body: %s", err)
+	}
+	assert.Equal(t, fiber.StatusInternalServerError, body.Code)
+	assert.Equal(t, apierror.CodeInternal, body.Error)
+	assert.Equal(t, "Internal Server Error", body.Message)
+}
+
+func TestErrorHandlerRendersProblemDetails(t *testing.T) {
+	req := httptest.NewRequest("GET", "/missing?page=2", nil)
+	req.Header.Set(fiber.HeaderAccept, middleware.MIMEProblemJSON)
+	req.Header.Set(middleware.RequestIDHeader, "trace-1")
+
+	resp, err := setupApp().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, middleware.MIMEProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+
+	var problem models.ProblemResponse
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode response body: %s", err)
+	}
+	assert.Equal(t, models.ProblemResponse{
+		Type:      "urn:newworld:error:not_found",
+		Title:     "Not Found",
+		Status:    fiber.StatusNotFound,
+		Detail:    "Cannot GET /missing",
+		Instance:  "/missing?page=2",
+		Code:      apierror.CodeNotFound,
+		RequestID: "trace-1",
+	}, problem)
+}
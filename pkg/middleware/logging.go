@@ -0,0 +1,58 @@
+// pkg/middleware/logging.go
+
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// probePaths are polled by Docker and Traefik, so they are only logged at debug level
+var probePaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// AccessLogMiddleware logs every request once it has been handled, including the user and role
+// set by the JWT middleware when the route is authenticated
+func AccessLogMiddleware(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+	status := responseStatus(c, err)
+
+	attrs := []slog.Attr{
+		slog.String("request_id", GetRequestID(c)),
+		slog.String("method", c.Method()),
+		slog.String("path", c.Path()),
+		slog.String("route", c.Route().Path),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("ip", c.IP()),
+	}
+	if span := trace.SpanContextFromContext(c.UserContext()); span.HasTraceID() {
+		attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+	}
+	if user, ok := c.Locals("user").(string); ok {
+		attrs = append(attrs, slog.String("user", user))
+	}
+	if role, ok := c.Locals("role").(string); ok {
+		attrs = append(attrs, slog.String("role", role))
+	}
+	if apiKey, ok := c.Locals("api_key").(string); ok {
+		attrs = append(attrs, slog.String("api_key", apiKey))
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		level = slog.LevelError
+	case probePaths[c.Path()]:
+		level = slog.LevelDebug
+	}
+	slog.LogAttrs(context.Background(), level, "request", attrs...)
+	return err
+}
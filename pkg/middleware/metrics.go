@@ -0,0 +1,33 @@
+// pkg/middleware/metrics.go
+
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MetricsMiddleware counts every request and observes its latency, labelled by route pattern
+// instead of path so IDs in the URL do not create a series per resource
+func MetricsMiddleware(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	labels := []string{c.Method(), c.Route().Path, strconv.Itoa(responseStatus(c, err))}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// responseStatus returns the status the response will be sent with once the handler chain returned err
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	// The error handler has not written the response yet, it answers with the status of the API error
+	return apierror.From(err).Status
+}
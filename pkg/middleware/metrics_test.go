@@ -0,0 +1,70 @@
+// pkg/middleware/metrics_test.go
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogAndMetricsRecordAPIErrorStatus(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Use(middleware.AccessLogMiddleware, middleware.MetricsMiddleware)
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		return apierror.NotFound("Order not found")
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	notFound := metrics.HTTPRequests.WithLabelValues("GET", "/orders/:id", "404")
+	failed := metrics.HTTPRequests.WithLabelValues("GET", "/boom", "500")
+	notFoundBefore, failedBefore := testutil.ToFloat64(notFound), testutil.ToFloat64(failed)
+
+	for _, path := range []string{"/orders/7", "/boom"} {
+		if _, err := app.Test(httptest.NewRequest("GET", path, nil)); err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+	}
+
+	// API errors keep their status, only unexpected errors count as internal ones
+	assert.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFound))
+	assert.Equal(t, failedBefore+1, testutil.ToFloat64(failed))
+
+	type entry struct {
+		Msg    string `json:"msg"`
+		Level  string `json:"level"`
+		Path   string `json:"path"`
+		Status int    `json:"status"`
+	}
+	var requests []entry
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var e entry
+		if err := decoder.Decode(&e); err != nil {
+			t.Fatalf("Failed to decode log entry: %s", err)
+		}
+		if e.Msg == "request" {
+			requests = append(requests, e)
+		}
+	}
+	assert.Equal(t, []entry{
+		{Msg: "request", Level: "INFO", Path: "/orders/7", Status: 404},
+		{Msg: "request", Level: "ERROR", Path: "/boom", Status: 500},
+	}, requests)
+}
@@ -1,37 +1,132 @@
 package middleware
 
 import (
-	"os"
+	"context"
+	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// JWTMiddleware validates the JWT token in the Authorization header
-func JWTMiddleware(c *fiber.Ctx) error {
-	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
-	if jwtSecretKey == "" {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "JWT secret key not found",
-		})
+// APIKeyHeader carries the API keys of machine clients, which send no JWT
+const APIKeyHeader = "X-API-Key"
+
+// UserLookup returns the user identified by id, reporting ok false for users that do not exist
+// or were deleted
+type UserLookup func(ctx context.Context, id uint) (user models.User, ok bool, err error)
+
+// APIKeyIdentity is who a request authenticated with an API key acts as
+type APIKeyIdentity struct {
+	UserID uint     // Owner of the key
+	Prefix string   // Tells the key apart in the access log
+	Scopes []string // What the key may do, see the models.Scope* constants
+}
+
+// APIKeyCheck authenticates an API key sent from ip, reporting ok false for keys that are unknown,
+// revoked or expired
+type APIKeyCheck func(ctx context.Context, key, ip string) (identity APIKeyIdentity, ok bool, err error)
+
+// NewJWTMiddleware returns a handler that validates the JWT token in the Authorization header
+// and refuses the tokens of suspended users. API keys are not accepted.
+func NewJWTMiddleware(cfg config.JWTConfig, users UserLookup) fiber.Handler {
+	return NewAuthMiddleware(cfg, users, nil)
+}
+
+// NewAuthMiddleware returns a handler that authenticates requests with either the JWT in the
+// Authorization header or the API key in the X-API-Key header, refusing suspended users.
+// Both name the user by ID, whose current email and role are looked up, and JWTs issued before
+// the token version of the user was incremented are refused.
+// Keys need the read scope for GET requests and the write scope for the others, and only act
+// as admins with the admin scope. Routes given a nil apiKeys accept JWTs only.
+func NewAuthMiddleware(cfg config.JWTConfig, users UserLookup, apiKeys APIKeyCheck) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		key := c.Get(APIKeyHeader)
+
+		var userID uint
+		var tokenVersion *uint // Only set for JWTs
+		var scopes []string    // Only set for API keys
+		switch {
+		case key != "" && authHeader != "":
+			return apierror.Unauthorized("Send either a JWT or an API key, not both")
+		case key != "" && apiKeys == nil:
+			return apierror.Forbidden("API keys cannot be used on this route, log in instead")
+		case key != "":
+			identity, ok, err := apiKeys(c.UserContext(), key, c.IP())
+			if err != nil {
+				return apierror.Internal("Failed to check API key", err)
+			}
+			if !ok {
+				return apierror.Unauthorized("Invalid, expired or revoked API key")
+			}
+			if scope := requiredScope(c.Method()); !hasScope(identity.Scopes, scope) {
+				return apierror.New(fiber.StatusForbidden, apierror.CodeForbidden, "API key lacks the %s scope", scope)
+			}
+			userID, scopes = identity.UserID, identity.Scopes
+			c.Locals("api_key", identity.Prefix)
+		default:
+			claims, err := parseJWT(cfg, authHeader)
+			if err != nil {
+				return err
+			}
+			id, version, ok := tokenSubject(claims)
+			if !ok {
+				return apierror.Unauthorized("Invalid or expired JWT")
+			}
+			userID, tokenVersion = id, &version
+		}
+
+		user, ok, err := users(c.UserContext(), userID)
+		if err != nil {
+			return apierror.Internal("Failed to check account", err)
+		}
+		if !ok {
+			return apierror.Unauthorized("Account not found")
+		}
+		if tokenVersion != nil && *tokenVersion != user.TokenVersion {
+			return apierror.Unauthorized("JWT token has been revoked")
+		}
+		if user.SuspendedAt != nil {
+			return apierror.New(fiber.StatusForbidden, apierror.CodeAccountSuspended, "Account suspended")
+		}
+
+		role := user.Role
+		if key != "" && !hasScope(scopes, models.ScopeAdmin) {
+			role = "user"
+		}
+		c.Locals("user_id", user.ID)
+		c.Locals("user", user.Email)
+		c.Locals("role", role)
+
+		return c.Next()
 	}
+}
+
+// tokenSubject returns the ID of the user a JWT was issued for and their token version at the time
+func tokenSubject(claims jwt.MapClaims) (id, version uint, ok bool) {
+	sub, _ := claims["sub"].(string)
+	parsed, err := strconv.ParseUint(sub, 10, 0)
+	ver, isNumber := claims["ver"].(float64)
+	if err != nil || parsed == 0 || !isNumber || ver < 0 {
+		return 0, 0, false
+	}
+	return uint(parsed), uint(ver), true
+}
 
-	authHeader := c.Get("Authorization")
+// parseJWT validates the bearer token in authHeader and returns its claims
+func parseJWT(cfg config.JWTConfig, authHeader string) (jwt.MapClaims, error) {
 	if authHeader == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Missing or malformed JWT",
-		})
+		return nil, apierror.Unauthorized("Missing or malformed JWT")
 	}
 
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Missing or malformed JWT",
-		})
+		return nil, apierror.Unauthorized("Missing or malformed JWT")
 	}
 
 	tokenString := parts[1]
@@ -40,37 +135,43 @@ func JWTMiddleware(c *fiber.Ctx) error {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fiber.NewError(fiber.StatusUnauthorized, "Unexpected signing method")
 		}
-		return []byte(jwtSecretKey), nil
+		return []byte(cfg.SecretKey), nil
 	})
 
 	if err != nil || !token.Valid {
 		if err != nil && strings.Contains(err.Error(), "expired") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "JWT token has expired",
-			})
+			return nil, apierror.New(fiber.StatusUnauthorized, apierror.CodeTokenExpired, "JWT token has expired")
 		}
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid or expired JWT",
-		})
+		return nil, apierror.Unauthorized("Invalid or expired JWT")
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	c.Locals("user", claims["email"])
-	c.Locals("role", claims["role"])
+	return token.Claims.(jwt.MapClaims), nil
+}
 
-	return c.Next()
+// requiredScope is the scope an API key needs to send a request with method
+func requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return models.ScopeRead
+	default:
+		return models.ScopeWrite
+	}
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // AdminMiddleware checks if the user has admin privileges
 func AdminMiddleware(c *fiber.Ctx) error {
 	role := c.Locals("role")
 	if role != "admin" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error":   true,
-			"message": "Access forbidden: Admins only",
-		})
+		return apierror.Forbidden("Access forbidden: Admins only")
 	}
 	return c.Next()
 }
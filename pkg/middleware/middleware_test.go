@@ -0,0 +1,203 @@
+// pkg/middleware/middleware_test.go
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// lookupUsers returns a UserLookup finding users in a map keyed by their ID
+func lookupUsers(users map[uint]models.User) middleware.UserLookup {
+	return func(ctx context.Context, id uint) (models.User, bool, error) {
+		user, ok := users[id]
+		return user, ok, nil
+	}
+}
+
+func testUser(id uint, email, role string) models.User {
+	user := models.User{Email: email, Role: role}
+	user.ID = id
+	return user
+}
+
+func TestJWTMiddlewareRefusesSuspendedUsers(t *testing.T) {
+	cfg := config.JWTConfig{SecretKey: "test-secret", TTL: time.Hour}
+	suspendedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mia, leo := testUser(1, "mia@example.com", "user"), testUser(2, "leo@example.com", "user")
+	leo.SuspendedAt = &suspendedAt
+	users := lookupUsers(map[uint]models.User{mia.ID: mia, leo.ID: leo})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Get("/me", middleware.NewJWTMiddleware(cfg, users), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user": c.Locals("user")})
+	})
+
+	request := func(user models.User) *http.Response {
+		token, err := utils.GenerateJWTTokenFunc(cfg, user)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %s", err)
+		}
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, request(mia).StatusCode)
+
+	resp := request(leo)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	var body models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	assert.Equal(t, apierror.CodeAccountSuspended, body.Error)
+}
+
+func TestJWTMiddlewareResolvesUsersByID(t *testing.T) {
+	cfg := config.JWTConfig{SecretKey: "test-secret", TTL: time.Hour}
+	mia := testUser(1, "mia@example.com", "user")
+	token, err := utils.GenerateJWTTokenFunc(cfg, mia)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %s", err)
+	}
+
+	users := map[uint]models.User{}
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Get("/me", middleware.NewJWTMiddleware(cfg, lookupUsers(users)), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user": c.Locals("user"), "role": c.Locals("role")})
+	})
+	request := func(token string) (*http.Response, fiber.Map) {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+		var body fiber.Map
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %s", err)
+		}
+		return resp, body
+	}
+
+	// The email and role come from the user the token names, not from the claims, so a token
+	// keeps following its user and never acts as whoever registers its old email
+	mia.Email, mia.Role = "mia@vault7.org", "admin"
+	users[mia.ID] = mia
+	users[2] = testUser(2, "mia@example.com", "user")
+	resp, body := request(token)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.Map{"user": "mia@vault7.org", "role": "admin"}, body)
+
+	// Incrementing the token version revokes the tokens issued before
+	mia.TokenVersion++
+	users[mia.ID] = mia
+	resp, body = request(token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "JWT token has been revoked", body["message"])
+
+	// Deleted users are not found
+	delete(users, mia.ID)
+	resp, _ = request(token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Tokens naming users by email only, as issued before, are refused
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email": "mia@example.com", "role": "user", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := legacy.SignedString([]byte(cfg.SecretKey))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %s", err)
+	}
+	resp, _ = request(signed)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuthMiddlewareAcceptsScopedAPIKeys(t *testing.T) {
+	cfg := config.JWTConfig{SecretKey: "test-secret", TTL: time.Hour}
+	mia := testUser(1, "mia@example.com", "admin")
+	users := lookupUsers(map[uint]models.User{mia.ID: mia})
+	keys := map[string]middleware.APIKeyIdentity{
+		"nwk_reader_secret": {UserID: mia.ID, Prefix: "nwk_reader", Scopes: []string{models.ScopeRead}},
+		"nwk_admin_secret":  {UserID: mia.ID, Prefix: "nwk_admin", Scopes: []string{models.ScopeRead, models.ScopeWrite, models.ScopeAdmin}},
+	}
+	apiKeys := func(ctx context.Context, key, ip string) (middleware.APIKeyIdentity, bool, error) {
+		identity, ok := keys[key]
+		return identity, ok, nil
+	}
+
+	whoami := func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user": c.Locals("user"), "role": c.Locals("role")})
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Get("/offers", middleware.NewAuthMiddleware(cfg, users, apiKeys), whoami)
+	app.Post("/checkout", middleware.NewAuthMiddleware(cfg, users, apiKeys), whoami)
+	app.Patch("/me", middleware.NewJWTMiddleware(cfg, users), whoami)
+
+	request := func(method, path, key, token string) (*http.Response, fiber.Map) {
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+		var body fiber.Map
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %s", err)
+		}
+		return resp, body
+	}
+
+	// Keys without the admin scope do not act as admins, whatever the role of their owner
+	resp, body := request("GET", "/offers", "nwk_reader_secret", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.Map{"user": "mia@example.com", "role": "user"}, body)
+	_, body = request("GET", "/offers", "nwk_admin_secret", "")
+	assert.Equal(t, "admin", body["role"])
+
+	resp, body = request("POST", "/checkout", "nwk_reader_secret", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "API key lacks the write scope", body["message"])
+	resp, _ = request("POST", "/checkout", "nwk_admin_secret", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = request("GET", "/offers", "nwk_unknown_secret", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	token, err := utils.GenerateJWTTokenFunc(cfg, mia)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %s", err)
+	}
+	resp, _ = request("GET", "/offers", "", token)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = request("GET", "/offers", "nwk_reader_secret", token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Routes that manage the account itself take JWTs only
+	resp, _ = request("PATCH", "/me", "nwk_admin_secret", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = request("PATCH", "/me", "", token)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
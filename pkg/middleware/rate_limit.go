@@ -0,0 +1,103 @@
+// pkg/middleware/rate_limit.go
+
+package middleware
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/ratelimit"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Headers telling clients about their rate limit
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset" // Seconds until the window starts over
+)
+
+// Route groups sharing a rate limit
+const (
+	RateLimitAnonymous = "anonymous"
+	RateLimitBuyer     = "buyer"
+	RateLimitAdmin     = "admin"
+)
+
+// NewRateLimitMiddleware returns a handler that allows rule.Requests requests per window to every
+// client of the group. Clients are the user set by the JWT middleware, so it must run after it on
+// authenticated routes, or the client IP otherwise. Requests are let through when the store fails,
+// an outage of the limiter should not take the API down with it.
+func NewRateLimitMiddleware(group string, rule config.RateLimitRule, store ratelimit.Store, clk clock.Clock) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := takeRateLimit(c, group, rule, store, clk); err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// LimitFailedAuth wraps the auth middleware so that every request it refuses as unauthenticated
+// counts against the anonymous limit of the client IP. The buyer and admin limits only count
+// authenticated requests, so clients guessing JWTs or API keys would otherwise never be limited.
+func LimitFailedAuth(auth fiber.Handler, cfg config.RateLimitConfig, store ratelimit.Store, clk clock.Clock) fiber.Handler {
+	if !cfg.Enabled {
+		return auth
+	}
+	return func(c *fiber.Ctx) error {
+		err := auth(c)
+		var apiErr *apierror.Error
+		if !errors.As(err, &apiErr) || apiErr.Status != fiber.StatusUnauthorized || c.Locals("user") != nil {
+			return err
+		}
+		if limitErr := takeRateLimit(c, RateLimitAnonymous, cfg.Anonymous, store, clk); limitErr != nil {
+			return limitErr
+		}
+		return err
+	}
+}
+
+// takeRateLimit counts the request against the limit of its client in group, setting the rate
+// limit headers, and returns the error answering it once the limit is reached
+func takeRateLimit(c *fiber.Ctx, group string, rule config.RateLimitRule, store ratelimit.Store, clk clock.Clock) error {
+	key := group + ":ip:" + c.IP()
+	if user, ok := c.Locals("user").(string); ok && user != "" {
+		key = group + ":user:" + user
+	}
+
+	now := clk.Now()
+	result, err := store.Take(c.UserContext(), key, rule.Requests, rule.Window, now)
+	if err != nil {
+		RequestLogger(c).Warn("Rate limiter unavailable, letting the request through", "group", group, "error", err)
+		return nil
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(result.Reset.Sub(now).Seconds())))
+	c.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Set(RateLimitResetHeader, reset)
+	if !result.Allowed {
+		metrics.RateLimited.WithLabelValues(group).Inc()
+		c.Set(fiber.HeaderRetryAfter, reset)
+		return apierror.New(fiber.StatusTooManyRequests, apierror.CodeRateLimited,
+			"Too many requests, retry in %s seconds", reset)
+	}
+	return nil
+}
+
+// RateLimiters returns the rate limit middleware of every route group, or handlers that
+// do nothing when rate limiting is disabled
+func RateLimiters(cfg config.RateLimitConfig, store ratelimit.Store, clk clock.Clock) (anonymous, buyer, admin fiber.Handler) {
+	if !cfg.Enabled {
+		next := func(c *fiber.Ctx) error { return c.Next() }
+		return next, next, next
+	}
+	return NewRateLimitMiddleware(RateLimitAnonymous, cfg.Anonymous, store, clk),
+		NewRateLimitMiddleware(RateLimitBuyer, cfg.Buyer, store, clk),
+		NewRateLimitMiddleware(RateLimitAdmin, cfg.Admin, store, clk)
+}
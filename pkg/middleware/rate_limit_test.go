@@ -0,0 +1,142 @@
+// pkg/middleware/rate_limit_test.go
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/ratelimit"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenStore is a rate limit backend that is down
+type brokenStore struct{}
+
+func (brokenStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func setupRateLimitedApp(store ratelimit.Store) *fiber.App {
+	clk := clock.Fixed(time.Date(2026, 10, 19, 12, 0, 15, 0, time.UTC))
+	rule := config.RateLimitRule{Requests: 2, Window: time.Minute}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	authenticate := func(c *fiber.Ctx) error {
+		if user := c.Get("X-Test-User"); user != "" {
+			c.Locals("user", user)
+		}
+		return c.Next()
+	}
+	app.Get("/limited", authenticate, middleware.NewRateLimitMiddleware(middleware.RateLimitBuyer, rule, store, clk), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+	return app
+}
+
+func getLimited(t *testing.T, app *fiber.App, user string) *http.Response {
+	req := httptest.NewRequest("GET", "/limited", nil)
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	return resp
+}
+
+func TestRateLimitRejectsOnceTheLimitIsReached(t *testing.T) {
+	app := setupRateLimitedApp(ratelimit.NewMemoryStore())
+
+	resp := getLimited(t, app, "mia@example.com")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(middleware.RateLimitLimitHeader))
+	assert.Equal(t, "1", resp.Header.Get(middleware.RateLimitRemainingHeader))
+	assert.Equal(t, "45", resp.Header.Get(middleware.RateLimitResetHeader))
+
+	assert.Equal(t, http.StatusOK, getLimited(t, app, "mia@example.com").StatusCode)
+	resp = getLimited(t, app, "mia@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "45", resp.Header.Get(fiber.HeaderRetryAfter))
+	assert.Equal(t, "0", resp.Header.Get(middleware.RateLimitRemainingHeader))
+
+	var body models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %s", err)
+	}
+	assert.Equal(t, apierror.CodeRateLimited, body.Error)
+
+	// Other users and anonymous clients have their own limit
+	assert.Equal(t, http.StatusOK, getLimited(t, app, "leo@example.com").StatusCode)
+	assert.Equal(t, http.StatusOK, getLimited(t, app, "").StatusCode)
+}
+
+func TestRateLimitLetsRequestsThroughWhenTheStoreFails(t *testing.T) {
+	app := setupRateLimitedApp(brokenStore{})
+
+	for i := 0; i < 3; i++ {
+		resp := getLimited(t, app, "mia@example.com")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(middleware.RateLimitLimitHeader))
+	}
+}
+
+func TestFailedAuthCountsAgainstTheAnonymousLimit(t *testing.T) {
+	jwtCfg := config.JWTConfig{SecretKey: "test-secret", TTL: time.Hour}
+	cfg := config.RateLimitConfig{Enabled: true, Anonymous: config.RateLimitRule{Requests: 2, Window: time.Minute}}
+	store := ratelimit.NewMemoryStore()
+	clk := clock.Fixed(time.Date(2026, 10, 19, 12, 0, 15, 0, time.UTC))
+	mia := models.User{Email: "mia@example.com", Role: "user"}
+	mia.ID = 1
+	users := func(ctx context.Context, id uint) (models.User, bool, error) { return mia, id == mia.ID, nil }
+	noAPIKeys := func(ctx context.Context, key, ip string) (middleware.APIKeyIdentity, bool, error) {
+		return middleware.APIKeyIdentity{}, false, nil
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	auth := middleware.LimitFailedAuth(middleware.NewAuthMiddleware(jwtCfg, users, noAPIKeys), cfg, store, clk)
+	app.Get("/me", auth, func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"user": c.Locals("user")})
+	})
+
+	token, err := utils.GenerateJWTTokenFunc(jwtCfg, mia)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %s", err)
+	}
+	request := func(header, value string) *http.Response {
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set(header, value)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+		return resp
+	}
+
+	// Authenticated requests leave the anonymous limit alone
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request("Authorization", "Bearer "+token).StatusCode)
+	}
+
+	// A wrong JWT and a wrong API key use up the limit of the client IP
+	resp := request("Authorization", "Bearer not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(middleware.RateLimitRemainingHeader))
+	assert.Equal(t, http.StatusUnauthorized, request(middleware.APIKeyHeader, "nw_guess").StatusCode)
+
+	resp = request("Authorization", "Bearer another-guess")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "45", resp.Header.Get(fiber.HeaderRetryAfter))
+}
@@ -0,0 +1,61 @@
+// pkg/middleware/request_id.go
+
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// RequestIDHeader carries the ID that correlates a request with its logs and error responses
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDLocal is the key of the request ID in the Fiber locals
+const requestIDLocal = "request_id"
+
+// maxRequestIDLength bounds the IDs accepted from clients and proxies
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware propagates the X-Request-ID sent by the client or a proxy, or generates one,
+// and echoes it in the response. ErrorHandler adds it to the body of every error response.
+func RequestIDMiddleware(c *fiber.Ctx) error {
+	id := c.Get(RequestIDHeader)
+	if !validRequestID(id) {
+		id = utils.UUIDv4()
+	} else {
+		// The header value points into a buffer Fiber reuses once the request is done
+		id = utils.CopyString(id)
+	}
+	c.Locals(requestIDLocal, id)
+	c.Set(RequestIDHeader, id)
+	return c.Next()
+}
+
+// GetRequestID returns the ID of the request, or an empty string outside RequestIDMiddleware
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDLocal).(string)
+	return id
+}
+
+// RequestLogger returns the default logger annotated with the ID of the request
+func RequestLogger(c *fiber.Ctx) *slog.Logger {
+	return slog.Default().With("request_id", GetRequestID(c))
+}
+
+// validRequestID only accepts short IDs made of characters that are safe to log and echo
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
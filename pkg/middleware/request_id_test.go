@@ -0,0 +1,79 @@
+// pkg/middleware/request_id_test.go
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Use(middleware.RequestIDMiddleware)
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+	app.Get("/bad", func(c *fiber.Ctx) error {
+		return apierror.Validation("Invalid request", models.FieldError{Field: "quantity", Rule: "gt", Message: "must be greater than 0"})
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+	return app
+}
+
+func TestRequestIDIsPropagated(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(middleware.RequestIDHeader, "trace-1234")
+
+	resp, err := setupApp().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	assert.Equal(t, "trace-1234", resp.Header.Get(middleware.RequestIDHeader))
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.NotContains(t, body, "request_id")
+}
+
+func TestRequestIDIsGeneratedForInvalidHeader(t *testing.T) {
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(middleware.RequestIDHeader, `"><script>`)
+
+	resp, err := setupApp().Test(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	id := resp.Header.Get(middleware.RequestIDHeader)
+	assert.NotEmpty(t, id)
+	assert.NotEqual(t, `"><script>`, id)
+}
+
+func TestErrorResponsesIncludeRequestID(t *testing.T) {
+	app := setupApp()
+
+	for _, path := range []string{"/bad", "/boom", "/missing"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(middleware.RequestIDHeader, "trace-"+path[1:])
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %s", err)
+		}
+
+		var body models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response body: %s", err)
+		}
+		assert.Equal(t, "trace-"+path[1:], body.RequestID)
+		assert.Equal(t, resp.StatusCode, body.Code)
+	}
+}
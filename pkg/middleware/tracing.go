@@ -0,0 +1,25 @@
+// pkg/middleware/tracing.go
+
+package middleware
+
+import (
+	"github.com/gofiber/contrib/otelfiber/v2"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NewTracingMiddleware starts a span for every request, continuing the W3C trace context sent by
+// Traefik. Handlers pass c.UserContext() to the database so queries are recorded as child spans.
+func NewTracingMiddleware() fiber.Handler {
+	return otelfiber.Middleware(
+		otelfiber.WithNext(func(c *fiber.Ctx) bool {
+			return probePaths[c.Path()]
+		}),
+		otelfiber.WithSpanNameFormatter(func(c *fiber.Ctx) string {
+			return c.Method() + " " + c.Route().Path
+		}),
+		otelfiber.WithCustomAttributes(func(c *fiber.Ctx) []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("http.request_id", GetRequestID(c))}
+		}),
+	)
+}
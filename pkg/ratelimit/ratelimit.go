@@ -0,0 +1,80 @@
+// pkg/ratelimit/ratelimit.go
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result tells whether a request was allowed and how much of the limit is left
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int       // Requests still allowed in the current window
+	Reset     time.Time // When the current window ends and the count starts over
+}
+
+// Store counts the requests of every key in fixed windows. The in-memory store only counts the
+// requests served by one replica, replicas that should share their limits need a backend such
+// as Redis, where Take maps to INCR and EXPIRE on a key holding the window start.
+type Store interface {
+	// Take counts a request for key at now against limit requests per window
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Result, error)
+}
+
+// counter is the request count of a key in its current window
+type counter struct {
+	count int
+	reset time.Time
+}
+
+// MemoryStore keeps the counters in the memory of the process
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]counter
+	lastPrune time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]counter)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now, window)
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.reset) {
+		c = counter{reset: windowEnd(now, window)}
+	}
+	if c.count < limit {
+		c.count++
+		s.counters[key] = c
+		return Result{Allowed: true, Limit: limit, Remaining: limit - c.count, Reset: c.reset}, nil
+	}
+	return Result{Allowed: false, Limit: limit, Remaining: 0, Reset: c.reset}, nil
+}
+
+// prune drops the counters of past windows once per window, so clients spraying IPs
+// cannot grow the store without bound
+func (s *MemoryStore) prune(now time.Time, window time.Duration) {
+	if now.Sub(s.lastPrune) < window {
+		return
+	}
+	s.lastPrune = now
+	for key, c := range s.counters {
+		if !now.Before(c.reset) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// windowEnd aligns windows to multiples of their length, so every replica sharing
+// a store agrees on when a window starts over
+func windowEnd(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window).Add(window)
+}
@@ -0,0 +1,43 @@
+// pkg/ratelimit/ratelimit_test.go
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func take(t *testing.T, store ratelimit.Store, key string, now time.Time) ratelimit.Result {
+	result, err := store.Take(context.Background(), key, 2, time.Minute, now)
+	if err != nil {
+		t.Fatalf("Failed to take: %s", err)
+	}
+	return result
+}
+
+func TestMemoryStoreLimitsEveryWindow(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+
+	first := take(t, store, "ip:10.0.0.1", start.Add(10*time.Second))
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.Equal(t, start.Add(time.Minute), first.Reset)
+
+	assert.True(t, take(t, store, "ip:10.0.0.1", start.Add(20*time.Second)).Allowed)
+	rejected := take(t, store, "ip:10.0.0.1", start.Add(30*time.Second))
+	assert.False(t, rejected.Allowed)
+	assert.Equal(t, 0, rejected.Remaining)
+
+	// Other keys have their own count
+	assert.True(t, take(t, store, "ip:10.0.0.2", start.Add(30*time.Second)).Allowed)
+
+	next := take(t, store, "ip:10.0.0.1", start.Add(time.Minute))
+	assert.True(t, next.Allowed)
+	assert.Equal(t, 1, next.Remaining)
+	assert.Equal(t, start.Add(2*time.Minute), next.Reset)
+}
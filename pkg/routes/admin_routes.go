@@ -4,19 +4,60 @@ package routes
 
 import (
 	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupAdminRoutes(app *fiber.App) {
+func SetupAdminRoutes(app *fiber.App, deps *container.Container) {
+	authMiddleware := newAuthMiddleware(deps)
+	jwtMiddleware := newJWTMiddleware(deps)
+	_, _, adminLimit := middleware.RateLimiters(deps.Config.RateLimit, deps.RateLimits, deps.Clock)
+	adminController := controllers.NewAdminController(deps)
 
-	db := database.GetDB()
-	adminController := controllers.NewAdminController(db)
+	// Protect these routes with a JWT, or an API key with the admin scope, and AdminMiddleware,
+	// limiting the requests of every admin
+	app.Get("/admin/dashboard", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.GetDashboard)
+	app.Patch("/admin/orders/:id", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.UpdateOrderStatus)
+	app.Get("/admin/users", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.GetAllUsers)
+	app.Delete("/admin/users", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.DeleteUsers)
+	app.Get("/admin/users/collisions", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.GetUserCollisions)
+	app.Get("/admin/users/:id", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.GetUser)
+	app.Delete("/admin/users/:id", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.DeleteUser)
+	app.Post("/admin/users/:id/suspend", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.SuspendUser)
+	app.Post("/admin/users/:id/unsuspend", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.UnsuspendUser)
+	app.Post("/admin/users/:id/restore", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.RestoreUser)
+	app.Delete("/admin/users/:id/purge", authMiddleware, middleware.AdminMiddleware, adminLimit, adminController.PurgeUser)
 
-	// Protect these routes with both JWTMiddleware and AdminMiddleware
-	app.Get("/admin/dashboard", middleware.JWTMiddleware, middleware.AdminMiddleware, adminController.GetDashboard)
-	app.Patch("/admin/orders/:id", middleware.JWTMiddleware, middleware.AdminMiddleware, adminController.UpdateOrderStatus)
-	app.Get("/admin/users", middleware.JWTMiddleware, middleware.AdminMiddleware, adminController.GetAllUsers)
-	app.Delete("/admin/users/:id", middleware.JWTMiddleware, middleware.AdminMiddleware, adminController.DeleteUser)
+	// API keys are only managed with a JWT, like the keys of users
+	apiKeyController := controllers.NewAPIKeyController(deps)
+	app.Get("/admin/api-keys", jwtMiddleware, middleware.AdminMiddleware, adminLimit, apiKeyController.GetAPIKeys)
+	app.Delete("/admin/api-keys/:id", jwtMiddleware, middleware.AdminMiddleware, adminLimit, apiKeyController.RevokeAPIKey)
+	app.Post("/admin/users/:id/api-keys", jwtMiddleware, middleware.AdminMiddleware, adminLimit, apiKeyController.CreateUserAPIKey)
+
+	lockoutController := controllers.NewLockoutController(deps)
+	app.Get("/admin/lockouts", authMiddleware, middleware.AdminMiddleware, adminLimit, lockoutController.GetLockouts)
+	app.Delete("/admin/lockouts/:kind/:subject", authMiddleware, middleware.AdminMiddleware, adminLimit, lockoutController.ClearLockout)
+
+	rationingController := controllers.NewRationingController(deps)
+	app.Get("/admin/rationing-rules", authMiddleware, middleware.AdminMiddleware, adminLimit, rationingController.GetRationingRules)
+	app.Post("/admin/rationing-rules", authMiddleware, middleware.AdminMiddleware, adminLimit, rationingController.CreateRationingRule)
+	app.Put("/admin/rationing-rules/:id", authMiddleware, middleware.AdminMiddleware, adminLimit, rationingController.UpdateRationingRule)
+	app.Delete("/admin/rationing-rules/:id", authMiddleware, middleware.AdminMiddleware, adminLimit, rationingController.DeleteRationingRule)
+
+	walletController := controllers.NewWalletController(deps)
+	app.Post("/admin/users/:id/credits", authMiddleware, middleware.AdminMiddleware, adminLimit, walletController.GrantCredits)
+
+	communityController := controllers.NewCommunityController(deps)
+	app.Get("/admin/communities", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.GetCommunities)
+	app.Post("/admin/communities", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.CreateCommunity)
+	app.Get("/admin/communities/trade-balance", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.GetTradeBalance)
+	app.Post("/admin/communities/:id/representatives", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.AddRepresentative)
+	app.Delete("/admin/communities/:id/representatives/:userId", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.RemoveRepresentative)
+	app.Post("/admin/communities/:id/settlements", authMiddleware, middleware.AdminMiddleware, adminLimit, communityController.RecordSettlement)
+
+	deliveryController := controllers.NewDeliveryController(deps)
+	app.Get("/admin/delivery-slots", authMiddleware, middleware.AdminMiddleware, adminLimit, deliveryController.GetDeliverySlots)
+	app.Post("/admin/delivery-slots", authMiddleware, middleware.AdminMiddleware, adminLimit, deliveryController.CreateDeliverySlot)
+	app.Put("/admin/orders/:id/delivery", authMiddleware, middleware.AdminMiddleware, adminLimit, deliveryController.AssignDelivery)
 }
@@ -0,0 +1,53 @@
+// pkg/routes/auth_middleware.go
+
+package routes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/services"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// newAuthMiddleware accepts JWTs and the API keys of machine clients. Routes that manage
+// credentials, such as passwords and API keys themselves, use the JWT middleware instead.
+// Failed attempts count against the anonymous limit of the client IP.
+func newAuthMiddleware(deps *container.Container) fiber.Handler {
+	auth := middleware.NewAuthMiddleware(deps.Config.JWT, lookupUser(deps), func(ctx context.Context, key, ip string) (middleware.APIKeyIdentity, bool, error) {
+		apiKey, _, err := deps.APIKeyService.Authenticate(ctx, key, ip)
+		if ruleErr, ok := services.AsRuleError(err); ok && ruleErr.Reason == services.ReasonInvalidToken {
+			return middleware.APIKeyIdentity{}, false, nil
+		}
+		if err != nil {
+			return middleware.APIKeyIdentity{}, false, err
+		}
+		return middleware.APIKeyIdentity{
+			UserID: apiKey.UserID,
+			Prefix: apiKey.Prefix,
+			Scopes: apiKey.ScopeList(),
+		}, true, nil
+	})
+	return middleware.LimitFailedAuth(auth, deps.Config.RateLimit, deps.RateLimits, deps.Clock)
+}
+
+// newJWTMiddleware accepts JWTs only, counting failed attempts against the anonymous limit of the client IP
+func newJWTMiddleware(deps *container.Container) fiber.Handler {
+	auth := middleware.NewJWTMiddleware(deps.Config.JWT, lookupUser(deps))
+	return middleware.LimitFailedAuth(auth, deps.Config.RateLimit, deps.RateLimits, deps.Clock)
+}
+
+// lookupUser finds the users named by JWTs and API keys, which are not found once deleted
+func lookupUser(deps *container.Container) middleware.UserLookup {
+	return func(ctx context.Context, id uint) (models.User, bool, error) {
+		user, err := deps.UserService.FindByID(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.User{}, false, nil
+		}
+		return user, err == nil, err
+	}
+}
@@ -4,20 +4,54 @@ package routes
 
 import (
 	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
 	"github.com/ICOMP-UNC/newworld-francoriba/pkg/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupAuthRoutes(app *fiber.App) {
-	db := database.GetDB()
+func SetupAuthRoutes(app *fiber.App, deps *container.Container) {
+	authMiddleware := newAuthMiddleware(deps)
+	jwtMiddleware := newJWTMiddleware(deps)
+	anonymousLimit, buyerLimit, _ := middleware.RateLimiters(deps.Config.RateLimit, deps.RateLimits, deps.Clock)
 
-	authController := controllers.NewAuthController(db)
-	app.Post("/auth/register", authController.Register)
-	app.Post("/auth/login", authController.Login)
+	authController := controllers.NewAuthController(deps)
+	app.Post("/auth/register", anonymousLimit, authController.Register)
+	app.Post("/auth/login", anonymousLimit, authController.Login)
 
-	// Protect these routes with JWTMiddleware
-	app.Get("/auth/offers", middleware.JWTMiddleware, authController.GetOffers)
-	app.Post("/auth/checkout", middleware.JWTMiddleware, authController.Checkout)
-	app.Get("/auth/orders/:id", middleware.JWTMiddleware, authController.GetOrderStatus)
+	// Protect these routes with a JWT or an API key, limiting the requests of every user
+	app.Get("/auth/offers", authMiddleware, buyerLimit, authController.GetOffers)
+	app.Post("/auth/checkout", authMiddleware, buyerLimit, authController.Checkout)
+	app.Get("/auth/orders/:id", authMiddleware, buyerLimit, authController.GetOrderStatus)
+	app.Post("/auth/orders/:id/cancel", authMiddleware, buyerLimit, authController.CancelOrder)
+
+	// Credentials and the account itself are only managed with a JWT, so a leaked API key cannot take it over
+	accountController := controllers.NewAccountController(deps)
+	app.Post("/auth/password", jwtMiddleware, buyerLimit, accountController.ChangePassword)
+	app.Post("/auth/password/forgot", anonymousLimit, accountController.ForgotPassword)
+	app.Post("/auth/password/reset", anonymousLimit, accountController.ResetPassword)
+	app.Post("/auth/email/verification", jwtMiddleware, buyerLimit, accountController.SendVerification)
+	app.Post("/auth/email/verify", anonymousLimit, accountController.VerifyEmail)
+
+	profileController := controllers.NewProfileController(deps)
+	app.Get("/auth/me", authMiddleware, buyerLimit, profileController.GetProfile)
+	app.Patch("/auth/me", jwtMiddleware, buyerLimit, profileController.UpdateProfile)
+	app.Delete("/auth/me", jwtMiddleware, buyerLimit, profileController.DeleteProfile)
+
+	apiKeyController := controllers.NewAPIKeyController(deps)
+	app.Get("/auth/api-keys", jwtMiddleware, buyerLimit, apiKeyController.GetMyAPIKeys)
+	app.Post("/auth/api-keys", jwtMiddleware, buyerLimit, apiKeyController.CreateMyAPIKey)
+	app.Delete("/auth/api-keys/:id", jwtMiddleware, buyerLimit, apiKeyController.RevokeMyAPIKey)
+
+	reservationController := controllers.NewReservationController(deps)
+	app.Post("/auth/reservations", authMiddleware, buyerLimit, reservationController.CreateReservation)
+	app.Post("/auth/reservations/:id/confirm", authMiddleware, buyerLimit, reservationController.ConfirmReservation)
+	app.Delete("/auth/reservations/:id", authMiddleware, buyerLimit, reservationController.ReleaseReservation)
+
+	walletController := controllers.NewWalletController(deps)
+	app.Get("/auth/wallet", authMiddleware, buyerLimit, walletController.GetWallet)
+	app.Get("/auth/wallet/transactions", authMiddleware, buyerLimit, walletController.GetWalletTransactions)
+
+	deliveryController := controllers.NewDeliveryController(deps)
+	app.Get("/auth/orders/:id/delivery", authMiddleware, buyerLimit, deliveryController.GetOrderDelivery)
+	app.Get("/auth/courier/deliveries", authMiddleware, buyerLimit, deliveryController.GetCourierDeliveries)
 }
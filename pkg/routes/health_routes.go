@@ -0,0 +1,17 @@
+// pkg/routes/health_routes.go
+
+package routes
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/app/controllers"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/container"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupHealthRoutes registers the unauthenticated probes used by Docker and Traefik
+func SetupHealthRoutes(app *fiber.App, deps *container.Container) {
+	healthController := controllers.NewHealthController(deps)
+	app.Get("/healthz", healthController.Healthz)
+	app.Get("/readyz", healthController.Readyz)
+	app.Get("/version", healthController.Version)
+}
@@ -0,0 +1,19 @@
+// pkg/routes/metrics_route.go
+
+package routes
+
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewMetricsApp returns the server exposing the Prometheus metrics. It listens on its own internal
+// port, which is neither published nor routed by Traefik, so only scrapers on the internal network
+// can read it.
+func NewMetricsApp() *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+	return app
+}
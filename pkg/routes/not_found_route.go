@@ -1,6 +1,9 @@
 package routes
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/apierror"
+	"github.com/gofiber/fiber/v2"
+)
 
 // NotFoundRoute func for describe 404 Error route.
 func NotFoundRoute(a *fiber.App) {
@@ -8,11 +11,8 @@ func NotFoundRoute(a *fiber.App) {
 	a.Use(
 		// Anonymous function.
 		func(c *fiber.Ctx) error {
-			// Return HTTP 404 status and JSON response.
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": true,
-				"msg":   "sorry, endpoint is not found",
-			})
+			// Return HTTP 404 status, rendered by the error handler.
+			return apierror.NotFound("sorry, endpoint is not found")
 		},
 	)
 }
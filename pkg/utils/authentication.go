@@ -3,30 +3,26 @@ package utils
 
 import (
 	"errors"
-	"os"
+	"strconv"
 	"time"
 
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 // Function variables to allow injection of mock implementations in tests
 var (
-	AuthenticateUserFunc       = authenticateUser
 	GenerateJWTTokenFunc       = generateJWTToken
 	BcryptGenerateFromPassword = bcrypt.GenerateFromPassword
 )
 
-// GenerateJWTToken generates a JWT token with the given email
-func generateJWTToken(email, role string) (string, error) {
-	// Retrieve the JWT secret key from the environment variable
-
-	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
-
-	if jwtSecretKey == "" {
+// GenerateJWTToken generates a JWT token for user. The token identifies the user by ID and carries
+// their token version, so it stops being accepted once the version is incremented. The email and
+// role are only there for clients to show, the auth middleware reads them from the database.
+func generateJWTToken(cfg config.JWTConfig, user models.User) (string, error) {
+	if cfg.SecretKey == "" {
 		return "", errors.New("JWT secret key not found")
 	}
 
@@ -34,12 +30,14 @@ func generateJWTToken(email, role string) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
 
-	claims["email"] = email
-	claims["role"] = role
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix() // Token expiration time (24 hours)
+	claims["sub"] = strconv.FormatUint(uint64(user.ID), 10)
+	claims["ver"] = user.TokenVersion
+	claims["email"] = user.Email
+	claims["role"] = user.Role
+	claims["exp"] = time.Now().Add(cfg.TTL).Unix() // Token expiration time
 
 	// Sign the token with the JWT secret key
-	tokenString, err := token.SignedString([]byte(jwtSecretKey))
+	tokenString, err := token.SignedString([]byte(cfg.SecretKey))
 
 	if err != nil {
 		return "", err
@@ -47,26 +45,3 @@ func generateJWTToken(email, role string) (string, error) {
 
 	return tokenString, nil
 }
-
-// AuthenticateUser authenticates a user with the given login credentials
-func authenticateUser(loginRequest models.LoginRequest) (models.User, error) {
-	// Query the database to find the user by email
-	db := database.GetDB()
-	var user models.User
-	if err := db.Where("email = ?", loginRequest.Email).First(&user).Error; err != nil {
-		// If user not found or an error occurs, return an error
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.User{}, errors.New("user not found")
-		}
-		return models.User{}, err
-	}
-
-	// Compare the hashed password from the database with the provided password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Password)); err != nil {
-		// If passwords don't match, return an error
-		return models.User{}, errors.New("invalid password")
-	}
-
-	// If authentication is successful, return the authenticated user
-	return user, nil
-}
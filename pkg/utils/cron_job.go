@@ -3,13 +3,23 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
-	"github.com/ICOMP-UNC/newworld-francoriba/pkg/database"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/clock"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/metrics"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/telemetry"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
 )
 
 const (
@@ -24,27 +34,52 @@ const (
 	CategoryFood     = "food"
 	CategoryDrink    = "drink"
 	CategoryMedicine = "medicine"
-
-	SuppliesURL = "http://192.168.0.57:8011/supplies?id=latest"
 )
 
-func FetchAndStoreSupplies() {
-	resp, err := http.Get(SuppliesURL)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		log.Printf("Failed to fetch supplies: %v", err)
-		return
+// SuppliesProvider retrieves the latest supplies available to the market
+type SuppliesProvider interface {
+	FetchSupplies(ctx context.Context) (models.SuppliesResponse, error)
+}
+
+// HTTPSuppliesProvider fetches the supplies from the /supplies endpoint of the HPCPP lab
+type HTTPSuppliesProvider struct {
+	URL  string
+	HTTP *http.Client
+}
+
+// NewHTTPSuppliesProvider creates a supplies provider from its configuration
+func NewHTTPSuppliesProvider(cfg config.SuppliesConfig) *HTTPSuppliesProvider {
+	return &HTTPSuppliesProvider{
+		URL:  cfg.URL,
+		HTTP: &http.Client{Timeout: cfg.Timeout, Transport: telemetry.HTTPTransport(http.DefaultTransport)},
 	}
-	defer resp.Body.Close()
+}
 
+func (p *HTTPSuppliesProvider) FetchSupplies(ctx context.Context) (models.SuppliesResponse, error) {
 	var supplies models.SuppliesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&supplies); err != nil {
-		log.Printf("Failed to decode supplies response: %v", err)
-		return
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return supplies, err
 	}
+	resp, err := p.HTTP.Do(req)
+	if err != nil {
+		return supplies, err
+	}
+	defer resp.Body.Close()
 
-	log.Println("Successfully fetched supplies")
-	db := database.GetDB()
+	if resp.StatusCode != http.StatusOK {
+		return supplies, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&supplies); err != nil {
+		return supplies, fmt.Errorf("failed to decode supplies response: %w", err)
+	}
+	return supplies, nil
+}
 
+// StoreSupplies turns the supplies into offers, updating the existing ones
+func StoreSupplies(db *gorm.DB, supplies models.SuppliesResponse) error {
 	offers := []models.Offer{
 		{Name: "fruits", Quantity: supplies.Food["fruits"] / 5, Price: PriceFruits, Category: CategoryFood},
 		{Name: "meat", Quantity: supplies.Food["meat"] / 5, Price: PriceMeat, Category: CategoryFood},
@@ -56,15 +91,105 @@ func FetchAndStoreSupplies() {
 	}
 
 	for _, offer := range offers {
-		db.Where(models.Offer{Name: offer.Name}).Assign(offer).FirstOrCreate(&offer)
+		if err := db.Where(models.Offer{Name: offer.Name}).Assign(offer).FirstOrCreate(&offer).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchAndStoreSupplies syncs the offers with the supplies reported by provider
+func FetchAndStoreSupplies(ctx context.Context, db *gorm.DB, provider SuppliesProvider) error {
+	supplies, err := provider.FetchSupplies(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch supplies", "error", err)
+		return err
+	}
+
+	slog.Info("Successfully fetched supplies", "food", len(supplies.Food), "medicine", len(supplies.Medicine))
+	if err := StoreSupplies(db.WithContext(ctx), supplies); err != nil {
+		slog.Error("Failed to store supplies", "error", err)
+		return err
+	}
+	return nil
+}
+
+// SuppliesSync runs the supplies sync and remembers when it last succeeded,
+// so readiness can tell whether the offers are stale
+type SuppliesSync struct {
+	DB       *gorm.DB
+	Provider SuppliesProvider
+	Clock    clock.Clock
+
+	mu          sync.RWMutex
+	lastSuccess time.Time
+}
+
+// NewSuppliesSync creates a sync that has not run yet
+func NewSuppliesSync(db *gorm.DB, provider SuppliesProvider, clk clock.Clock) *SuppliesSync {
+	return &SuppliesSync{DB: db, Provider: provider, Clock: clk}
+}
+
+// Run fetches and stores the supplies once
+func (s *SuppliesSync) Run() error {
+	// Each sync is a trace of its own, with the HPCPP request and the queries as children
+	ctx, span := telemetry.Tracer().Start(context.Background(), "supplies.sync")
+	defer span.End()
+
+	start := time.Now()
+	err := FetchAndStoreSupplies(ctx, s.DB, s.Provider)
+	metrics.SuppliesSyncDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "supplies sync failed")
+		metrics.SuppliesSyncs.WithLabelValues("failure").Inc()
+		return err
+	}
+	metrics.SuppliesSyncs.WithLabelValues("success").Inc()
+
+	s.mu.Lock()
+	s.lastSuccess = s.Clock.Now()
+	s.mu.Unlock()
+	metrics.SuppliesLastSuccess.Set(float64(s.LastSuccess().Unix()))
+	return nil
+}
+
+// LastSuccess returns when the supplies were last synced, or the zero time if they never were
+func (s *SuppliesSync) LastSuccess() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastSuccess
+}
+
+// ReservationExpirer marks the reservations whose TTL has passed as expired and counts them
+type ReservationExpirer func(ctx context.Context) (int64, error)
+
+// ReleaseExpiredReservations runs expire and logs how many reservations it released
+func ReleaseExpiredReservations(expire ReservationExpirer) {
+	expired, err := expire(context.Background())
+	if err != nil {
+		slog.Error("Failed to release expired reservations", "error", err)
+		return
+	}
+	if expired > 0 {
+		slog.Info("Released expired reservations", "count", expired)
 	}
 }
 
-func StartCronJob() {
+// StartCronJob schedules the supplies sync and the release of expired reservations.
+// The returned scheduler must be stopped on shutdown so running jobs can finish.
+func StartCronJob(cfg config.SuppliesConfig, reservations config.ReservationsConfig, supplies *SuppliesSync, expire ReservationExpirer) *cron.Cron {
 	c := cron.New()
-	_, err := c.AddFunc("@hourly", FetchAndStoreSupplies)
+	_, err := c.AddFunc(cfg.Schedule, func() { supplies.Run() })
+	if err != nil {
+		slog.Error("Error starting cron job", "schedule", cfg.Schedule, "error", err)
+		os.Exit(1)
+	}
+	_, err = c.AddFunc(reservations.ExpirySchedule, func() { ReleaseExpiredReservations(expire) })
 	if err != nil {
-		log.Fatalf("Error starting cron job: %v", err)
+		slog.Error("Error starting reservation cron job", "schedule", reservations.ExpirySchedule, "error", err)
+		os.Exit(1)
 	}
 	c.Start()
+	return c
 }
@@ -0,0 +1,127 @@
+// pkg/validation/password.go
+
+package validation
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+)
+
+// Rules reported when a password breaks the policy, in the order they are checked
+const (
+	RulePasswordTooShort = "password_too_short"
+	RulePasswordTooLong  = "password_too_long"
+	RulePasswordClasses  = "password_character_classes"
+	RulePasswordIdentity = "password_contains_identity"
+	RulePasswordCommon   = "password_common"
+)
+
+// minIdentityPartLength is the shortest piece of a username or email looked for in passwords
+const minIdentityPartLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+// commonPasswords holds the bundled list of common passwords, in lowercase
+var commonPasswords = parseCommonPasswords(commonPasswordsList)
+
+// PasswordPolicy decides which passwords users may choose
+type PasswordPolicy struct {
+	cfg config.PasswordConfig
+}
+
+// NewPasswordPolicy builds the policy described by cfg
+func NewPasswordPolicy(cfg config.PasswordConfig) PasswordPolicy {
+	return PasswordPolicy{cfg: cfg}
+}
+
+// PasswordViolation describes the first rule of the policy a password breaks
+type PasswordViolation struct {
+	Rule    string
+	Message string
+}
+
+// Check returns the first rule password breaks, or nil when it is acceptable. Identities are
+// the username and email of the account, which the password must not contain.
+func (p PasswordPolicy) Check(password string, identities ...string) *PasswordViolation {
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		return &PasswordViolation{RulePasswordTooShort, fmt.Sprintf("must be at least %d characters long", p.cfg.MinLength)}
+	}
+	if len(password) > config.MaxPasswordBytes {
+		return &PasswordViolation{RulePasswordTooLong, fmt.Sprintf("must be at most %d bytes long", config.MaxPasswordBytes)}
+	}
+	if characterClasses(password) < p.cfg.MinClasses {
+		return &PasswordViolation{RulePasswordClasses, fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.cfg.MinClasses)}
+	}
+
+	lower := strings.ToLower(password)
+	for _, identity := range identities {
+		for _, part := range identityParts(identity) {
+			if strings.Contains(lower, part) {
+				return &PasswordViolation{RulePasswordIdentity, "must not contain the username or email"}
+			}
+		}
+	}
+	if p.cfg.CheckCommon && commonPasswords[lower] {
+		return &PasswordViolation{RulePasswordCommon, "is too common, choose a less predictable password"}
+	}
+	return nil
+}
+
+// characterClasses counts how many of lowercase letters, uppercase letters, digits and symbols password uses
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	count := 0
+	for _, used := range []bool{lower, upper, digit, symbol} {
+		if used {
+			count++
+		}
+	}
+	return count
+}
+
+// identityParts returns the lowercase pieces of a username or email a password must not contain:
+// the whole value and, for emails, the part before the @. Pieces too short to be telling are skipped.
+func identityParts(identity string) []string {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	var parts []string
+	if len(identity) >= minIdentityPartLength {
+		parts = append(parts, identity)
+	}
+	if local, _, ok := strings.Cut(identity, "@"); ok && len(local) >= minIdentityPartLength {
+		parts = append(parts, local)
+	}
+	return parts
+}
+
+// parseCommonPasswords reads one password per line, skipping blank lines and # comments
+func parseCommonPasswords(list string) map[string]bool {
+	passwords := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = true
+	}
+	return passwords
+}
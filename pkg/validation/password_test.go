@@ -0,0 +1,71 @@
+// pkg/validation/password_test.go
+package validation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := validation.NewPasswordPolicy(config.PasswordConfig{MinLength: 10, MinClasses: 3, CheckCommon: true})
+
+	tests := []struct {
+		name     string
+		password string
+		rule     string
+	}{
+		{"acceptable", "Gr33n-Valley!", ""},
+		{"too short", "Ab1!", validation.RulePasswordTooShort},
+		{"too long for bcrypt", "Aa1!" + string(make([]byte, 69)), validation.RulePasswordTooLong},
+		{"counts characters, not bytes", "Ñandú-Río-1", ""},
+		{"too few character classes", "onlylowercaseletters", validation.RulePasswordClasses},
+		{"contains the username", "Survivor-Mia-99", validation.RulePasswordIdentity},
+		{"contains the email local part", "xMIA.ROSSIx-2026", validation.RulePasswordIdentity},
+		{"common regardless of case", "Password123!", validation.RulePasswordCommon},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := policy.Check(tt.password, "mia", "mia.rossi@example.com")
+			if tt.rule == "" {
+				assert.Nil(t, violation)
+				return
+			}
+			if assert.NotNil(t, violation) {
+				assert.Equal(t, tt.rule, violation.Rule)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyCanSkipCommonList(t *testing.T) {
+	policy := validation.NewPasswordPolicy(config.PasswordConfig{MinLength: 8, CheckCommon: false})
+
+	assert.Nil(t, policy.Check("password123!"))
+}
+
+func TestRegisterRequestRejectsPasswordWithUsername(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.RegisterRequest{
+		Username: "rescuer",
+		Email:    "new@example.com",
+		Password: "Rescuer-2026!",
+	})
+	assert.Equal(t, validation.Errors{
+		{Field: "password", Rule: validation.RulePasswordIdentity, Message: "must not contain the username or email"},
+	}, fieldErrors(t, err))
+}
+
+func TestPasswordReportsField(t *testing.T) {
+	v := setupValidator(t)
+
+	assert.NoError(t, v.Password("new_password", "Gr33n-Valley!", "rescuer", "new@example.com"))
+	assert.Equal(t, validation.Errors{
+		{Field: "new_password", Rule: validation.RulePasswordCommon, Message: "is too common, choose a less predictable password"},
+	}, v.Password("new_password", "P@ssw0rd123", "rescuer", "new@example.com"))
+}
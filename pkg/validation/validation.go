@@ -0,0 +1,266 @@
+// pkg/validation/validation.go
+
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom rules registered on top of the validator built-ins
+const (
+	RuleUniqueUsername = "unique_username"
+	RuleUniqueEmail    = "unique_email"
+	RulePassword       = "password"
+)
+
+// Errors lists every field of a request that broke a rule
+type Errors []models.FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return "invalid request: " + strings.Join(messages, ", ")
+}
+
+// AsErrors reports whether err lists invalid fields, and returns them
+func AsErrors(err error) (Errors, bool) {
+	var fieldErrs Errors
+	ok := errors.As(err, &fieldErrs)
+	return fieldErrs, ok
+}
+
+// Validator checks request bodies against their `validate` tags. Rules that need the
+// database, like unique_username, run against the store.
+type Validator struct {
+	validate  *validator.Validate
+	store     repositories.Store
+	passwords PasswordPolicy
+}
+
+// New builds a validator whose uniqueness rules look users up in store and whose
+// password rule enforces the given policy
+func New(store repositories.Store, passwords config.PasswordConfig) *Validator {
+	v := &Validator{
+		validate:  validator.New(validator.WithRequiredStructEnabled()),
+		store:     store,
+		passwords: NewPasswordPolicy(passwords),
+	}
+
+	// Report fields by the name clients send, not by the Go field name
+	v.validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.validate.RegisterValidationCtx(RuleUniqueUsername, v.uniqueUser(func(users repositories.UserRepository, value string) error {
+		_, err := users.FindByUsername(value)
+		return err
+	}))
+	v.validate.RegisterValidationCtx(RuleUniqueEmail, v.uniqueUser(func(users repositories.UserRepository, value string) error {
+		_, err := users.FindByEmail(value)
+		return err
+	}))
+	v.validate.RegisterValidationCtx(RulePassword, v.password)
+	return v
+}
+
+// Struct validates request and returns Errors listing every invalid field. Any other
+// error means a rule could not be checked, e.g. because the database is down.
+func (v *Validator) Struct(ctx context.Context, request interface{}) error {
+	state := &runState{}
+	err := v.validate.StructCtx(context.WithValue(ctx, runStateKey{}, state), request)
+	if state.lookupErr != nil {
+		return state.lookupErr
+	}
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	fieldErrs := make(Errors, len(validationErrs))
+	violations := state.passwords
+	for i, fieldErr := range validationErrs {
+		fieldErrs[i] = models.FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Rule:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		}
+		// Password errors come in the same order the rule found them, so they can say which part of the policy failed
+		if fieldErr.Tag() == RulePassword && len(violations) > 0 {
+			fieldErrs[i].Rule, fieldErrs[i].Message = violations[0].Rule, violations[0].Message
+			violations = violations[1:]
+		}
+	}
+	return fieldErrs
+}
+
+// Password checks a password set outside a request struct against the policy, reporting it
+// as field. Identities are the username and email of the account.
+func (v *Validator) Password(field, password string, identities ...string) error {
+	if violation := v.passwords.Check(password, identities...); violation != nil {
+		return Errors{{Field: field, Rule: violation.Rule, Message: violation.Message}}
+	}
+	return nil
+}
+
+// runStateKey carries the runState of a Struct call through the context of StructCtx
+type runStateKey struct{}
+
+// runState keeps what the custom rules of one Struct call learn beyond valid or invalid:
+// the first error a database backed rule ran into and why passwords were rejected.
+// The validator runs the rules of a struct one after the other.
+type runState struct {
+	lookupErr error
+	passwords []PasswordViolation
+}
+
+func stateFrom(ctx context.Context) *runState {
+	if state, ok := ctx.Value(runStateKey{}).(*runState); ok {
+		return state
+	}
+	return &runState{}
+}
+
+// uniqueUser builds a rule that passes when find does not find any user with the field value
+func (v *Validator) uniqueUser(find func(repositories.UserRepository, string) error) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		err := find(v.store.WithContext(ctx).Users(), fl.Field().String())
+		if errors.Is(err, repositories.ErrNotFound) {
+			return true
+		}
+		if state := stateFrom(ctx); err != nil && state.lookupErr == nil {
+			state.lookupErr = err
+		}
+		return false
+	}
+}
+
+// password checks a password against the policy, not letting it contain the username
+// or email sent in the same request
+func (v *Validator) password(ctx context.Context, fl validator.FieldLevel) bool {
+	violation := v.passwords.Check(fl.Field().String(), identities(fl.Parent())...)
+	if violation == nil {
+		return true
+	}
+	state := stateFrom(ctx)
+	state.passwords = append(state.passwords, *violation)
+	return false
+}
+
+// identities returns the username and email fields of a request struct
+func identities(request reflect.Value) []string {
+	for request.Kind() == reflect.Pointer {
+		request = request.Elem()
+	}
+	if request.Kind() != reflect.Struct {
+		return nil
+	}
+	var values []string
+	for i := 0; i < request.NumField(); i++ {
+		switch strings.SplitN(request.Type().Field(i).Tag.Get("json"), ",", 2)[0] {
+		case "username", "email":
+			if field := request.Field(i); field.Kind() == reflect.String {
+				values = append(values, field.String())
+			}
+		}
+	}
+	return values
+}
+
+// fieldPath drops the name of the request struct from a namespace like CheckoutRequest.items[0].quantity
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// message describes a broken rule for humans
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required_without", "excluded_with", "gtfield", "unique":
+		// These take the Go name of another field, which clients never see
+		param = snakeCase(param)
+	}
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not set", param)
+	case "excluded_with":
+		return fmt.Sprintf("must not be set together with %s", param)
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isCollection(fieldErr.Kind()) {
+			return fmt.Sprintf("must contain at least %s items", param)
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return fmt.Sprintf("must be at least %s", param)
+	case "max":
+		if isCollection(fieldErr.Kind()) {
+			return fmt.Sprintf("must contain at most %s items", param)
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return fmt.Sprintf("must be at most %s", param)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "gtfield":
+		return fmt.Sprintf("must be after %s", param)
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "unique":
+		if param == "" {
+			return "must not repeat the same value"
+		}
+		return fmt.Sprintf("must not repeat the same %s", param)
+	case RuleUniqueUsername:
+		return "is already taken"
+	case RuleUniqueEmail:
+		return "is already registered"
+	case RulePassword:
+		return "does not meet the password policy"
+	}
+	return "is invalid (" + fieldErr.Tag() + ")"
+}
+
+// snakeCase turns a Go field name like OfferID into the JSON name offer_id
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		upper := unicode.IsUpper(r)
+		if upper && i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func isCollection(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
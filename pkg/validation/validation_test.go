@@ -0,0 +1,108 @@
+// pkg/validation/validation_test.go
+package validation_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ICOMP-UNC/newworld-francoriba/app/models"
+	"github.com/ICOMP-UNC/newworld-francoriba/app/repositories"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/config"
+	"github.com/ICOMP-UNC/newworld-francoriba/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupValidator(t *testing.T) *validation.Validator {
+	store := repositories.NewMemoryStore()
+	taken := models.User{Username: "taken", Email: "taken@example.com", Role: "user"}
+	if err := store.Users().Create(&taken); err != nil {
+		t.Fatalf("Failed to create user: %s", err)
+	}
+	return validation.New(store, config.Default().Password)
+}
+
+func fieldErrors(t *testing.T, err error) validation.Errors {
+	fieldErrs, ok := validation.AsErrors(err)
+	if !ok {
+		t.Fatalf("Expected field errors, got %v", err)
+	}
+	return fieldErrs
+}
+
+func TestRegisterRequestIsValid(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.RegisterRequest{
+		Username: "newuser",
+		Email:    "new@example.com",
+		Password: "Gr33n-Valley!",
+	})
+	assert.NoError(t, err)
+}
+
+func TestRegisterRequestListsEveryInvalidField(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.RegisterRequest{
+		Username: "taken",
+		Email:    "not-an-email",
+		Password: strings.Repeat("Aa1!", 19),
+	})
+	assert.Equal(t, validation.Errors{
+		{Field: "username", Rule: validation.RuleUniqueUsername, Message: "is already taken"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "password", Rule: validation.RulePasswordTooLong, Message: "must be at most 72 bytes long"},
+	}, fieldErrors(t, err))
+}
+
+func TestRegisterRequestRejectsTakenEmail(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.RegisterRequest{
+		Username: "newuser",
+		Email:    "taken@example.com",
+		Password: "Gr33n-Valley!",
+	})
+	assert.Equal(t, validation.Errors{
+		{Field: "email", Rule: validation.RuleUniqueEmail, Message: "is already registered"},
+	}, fieldErrors(t, err))
+}
+
+func TestCheckoutRequestRejectsDuplicateOffers(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.CheckoutRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+		{OfferID: 1, Quantity: 2},
+	}})
+	assert.Equal(t, validation.Errors{
+		{Field: "items", Rule: "unique", Message: "must not repeat the same offer_id"},
+	}, fieldErrors(t, err))
+}
+
+func TestCheckoutRequestValidatesItems(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.CheckoutRequest{Items: []models.CheckoutItem{
+		{OfferID: 1, Quantity: 1},
+		{OfferID: 2},
+	}})
+	assert.Equal(t, validation.Errors{
+		{Field: "items[1].quantity", Rule: "required", Message: "is required"},
+	}, fieldErrors(t, err))
+
+	err = v.Struct(context.Background(), models.CheckoutRequest{Items: []models.CheckoutItem{}})
+	assert.Equal(t, validation.Errors{
+		{Field: "items", Rule: "min", Message: "must contain at least 1 items"},
+	}, fieldErrors(t, err))
+}
+
+func TestUpdateOrderStatusRequestRejectsUnknownStatus(t *testing.T) {
+	v := setupValidator(t)
+
+	err := v.Struct(context.Background(), models.UpdateOrderStatusRequest{Status: "lost"})
+	assert.Equal(t, validation.Errors{
+		{Field: "status", Rule: "oneof", Message: "must be one of: preparing, processing, shipped, delivered, cancelled"},
+	}, fieldErrors(t, err))
+}
@@ -0,0 +1,10 @@
+// pkg/version/version.go
+
+package version
+
+// Build information, set at link time with
+// -ldflags "-X github.com/ICOMP-UNC/newworld-francoriba/pkg/version.Commit=... -X github.com/ICOMP-UNC/newworld-francoriba/pkg/version.BuildTime=..."
+var (
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)